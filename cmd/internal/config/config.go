@@ -0,0 +1,21 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// CmdConfig represents the config command.
+var CmdConfig = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the config files",
+	Long:  "Manage the config files.",
+}
+
+func init() {
+	CmdConfig.AddCommand(CmdEncrypt)
+	CmdConfig.AddCommand(CmdDecrypt)
+	CmdConfig.AddCommand(CmdSchema)
+	CmdConfig.AddCommand(CmdDump)
+	CmdConfig.AddCommand(CmdDiff)
+	CmdConfig.AddCommand(CmdLint)
+}
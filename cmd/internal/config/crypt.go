@@ -0,0 +1,111 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/banbridge/common/pkg/config"
+)
+
+var (
+	encryptKeyRing string
+	encryptKeyID   string
+	decryptKeyRing string
+)
+
+// CmdEncrypt represents the config encrypt command.
+var CmdEncrypt = &cobra.Command{
+	Use:   "encrypt [value...]",
+	Short: "Encrypt config values",
+	Long: "Encrypt config values into ENC[aes256gcm:id:data] with the primary key of the key ring, " +
+		"values are read from stdin, one per line, when no argument is given. " +
+		"Example: petal config encrypt --keyring keys.txt s3cr3t",
+	RunE: runCrypt(&encryptKeyRing, &encryptKeyID, func(k *config.KeyRing, v string) (string, error) {
+		return k.Encrypt(v)
+	}),
+}
+
+// CmdDecrypt represents the config decrypt command.
+var CmdDecrypt = &cobra.Command{
+	Use:   "decrypt [value...]",
+	Short: "Decrypt config values",
+	Long: "Decrypt ENC[aes256gcm:id:data] config values with the key ring, " +
+		"values are read from stdin, one per line, when no argument is given. " +
+		"Example: petal config decrypt --keyring keys.txt 'ENC[aes256gcm:k1:...]'",
+	RunE: runCrypt(&decryptKeyRing, nil, func(k *config.KeyRing, v string) (string, error) {
+		return k.Decrypt(v)
+	}),
+}
+
+func init() {
+	CmdEncrypt.Flags().StringVar(&encryptKeyRing, "keyring", "", "key ring file, the "+config.KeyRingEnv+" variable when empty")
+	CmdEncrypt.Flags().StringVar(&encryptKeyID, "key-id", "", "id of the key to encrypt with, the primary key when empty")
+	CmdDecrypt.Flags().StringVar(&decryptKeyRing, "keyring", "", "key ring file, the "+config.KeyRingEnv+" variable when empty")
+}
+
+// loadKeyRing loads the key ring file path, or the key ring of the
+// environment when path is empty, with keyID as primary key if set.
+func loadKeyRing(path, keyID string) (*config.KeyRing, error) {
+	var (
+		k   *config.KeyRing
+		err error
+	)
+	if path != "" {
+		k, err = config.LoadKeyRing(path)
+	} else {
+		k, err = config.KeyRingFromEnv("")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if keyID != "" {
+		if err := k.SetPrimary(keyID); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// runCrypt returns the command applying fn to the values with the key ring
+// of the flags keyRing and keyID, keyID may be nil.
+func runCrypt(keyRing, keyID *string, fn func(*config.KeyRing, string) (string, error)) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		var id string
+		if keyID != nil {
+			id = *keyID
+		}
+		k, err := loadKeyRing(*keyRing, id)
+		if err != nil {
+			return err
+		}
+		values := args
+		if len(values) == 0 {
+			if values, err = readLines(cmd.InOrStdin()); err != nil {
+				return err
+			}
+		}
+		for _, v := range values {
+			out, err := fn(k, v)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), out)
+		}
+		return nil
+	}
+}
+
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
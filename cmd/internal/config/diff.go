@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/spf13/cobra"
+
+	"github.com/banbridge/common/pkg/config"
+)
+
+// CmdDiff represents the config diff command.
+var CmdDiff = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "Compare config files",
+	Long: "Compare the keys and values of two config files, directories or glob patterns, whatever " +
+		"their formats, placeholders are compared unresolved. Example: petal config diff config.yaml config.prod.json",
+	Args: cobra.ExactArgs(2),
+	RunE: runDiff,
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	prev, err := decodeFiles(args[0])
+	if err != nil {
+		return err
+	}
+	next, err := decodeFiles(args[1])
+	if err != nil {
+		return err
+	}
+	for _, c := range config.Diff(prev, next) {
+		switch c.Type {
+		case config.ChangeAdded:
+			fmt.Fprintf(cmd.OutOrStdout(), "+ %s: %s\n", c.Path, formatValue(c.New))
+		case config.ChangeRemoved:
+			fmt.Fprintf(cmd.OutOrStdout(), "- %s: %s\n", c.Path, formatValue(c.Old))
+		case config.ChangeModified:
+			fmt.Fprintf(cmd.OutOrStdout(), "~ %s: %s -> %s\n", c.Path, formatValue(c.Old), formatValue(c.New))
+		}
+	}
+	return nil
+}
+
+func decodeFiles(path string) (map[string]interface{}, error) {
+	kvs, err := loadFiles([]string{path})
+	if err != nil {
+		return nil, err
+	}
+	return config.Decode(kvs)
+}
+
+func formatValue(v interface{}) string {
+	data, err := sonic.ConfigStd.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bytedance/sonic"
+	"github.com/spf13/cobra"
+
+	"github.com/banbridge/common/pkg/config"
+	"github.com/banbridge/common/pkg/config/env"
+	"github.com/banbridge/common/pkg/config/file"
+	"github.com/banbridge/common/pkg/encoding"
+)
+
+var (
+	dumpFiles        []string
+	dumpEnvPrefixes  []string
+	dumpDotEnv       []string
+	dumpEnvSeparator string
+	dumpEnvLower     bool
+	dumpFormat       string
+	dumpKeyRing      string
+)
+
+// CmdDump represents the config dump command.
+var CmdDump = &cobra.Command{
+	Use:   "dump",
+	Short: "Print the merged config",
+	Long: "Load the config files, then the environment variables, the way a service does, resolve the " +
+		"placeholders and secrets and print the merged config with the secrets redacted. The ENC values " +
+		"are printed redacted without a key ring. " +
+		"Example: petal config dump -f configs/ --env APP_ --env-separator _ --env-lower",
+	Args: cobra.NoArgs,
+	RunE: runDump,
+}
+
+func init() {
+	CmdDump.Flags().StringArrayVarP(&dumpFiles, "file", "f", nil, "config file, directory or glob pattern, can be repeated")
+	CmdDump.Flags().StringArrayVar(&dumpEnvPrefixes, "env", nil, "load the environment variables with this prefix, can be repeated")
+	CmdDump.Flags().StringArrayVar(&dumpDotEnv, "dotenv", nil, ".env file loaded with the environment variables, can be repeated")
+	CmdDump.Flags().StringVar(&dumpEnvSeparator, "env-separator", "", "separator of the nested keys in variable names")
+	CmdDump.Flags().BoolVar(&dumpEnvLower, "env-lower", false, "lower-case the keys of the variables")
+	CmdDump.Flags().StringVar(&dumpFormat, "format", "yaml", "output format, yaml or json")
+	CmdDump.Flags().StringVar(&dumpKeyRing, "keyring", "", "key ring file of the ENC values, the "+config.KeyRingEnv+" variable when empty")
+}
+
+func runDump(cmd *cobra.Command, _ []string) error {
+	var opts []config.Option
+	var sources []config.Source
+	for _, path := range dumpFiles {
+		sources = append(sources, file.NewSource(path))
+	}
+	if len(dumpEnvPrefixes) > 0 || len(dumpDotEnv) > 0 {
+		envOpts := []env.Option{env.WithPrefixes(dumpEnvPrefixes...), env.WithDotEnv(dumpDotEnv...)}
+		if dumpEnvSeparator != "" {
+			envOpts = append(envOpts, env.WithSeparator(dumpEnvSeparator))
+		}
+		if dumpEnvLower {
+			envOpts = append(envOpts, env.WithLowerCase())
+		}
+		sources = append(sources, env.New(envOpts...))
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("no config source, use --file or --env")
+	}
+	opts = append(opts, config.WithSource(sources...))
+	if _, ok := os.LookupEnv(config.KeyRingEnv); ok || dumpKeyRing != "" {
+		k, err := loadKeyRing(dumpKeyRing, "")
+		if err != nil {
+			return err
+		}
+		opts = append(opts, config.WithKeyRing(k))
+	} else {
+		opts = append(opts, config.WithEncryptedRedacted())
+	}
+
+	c := config.New(opts...)
+	if err := c.Load(); err != nil {
+		return err
+	}
+	defer c.Close()
+	data, err := config.Dump(c)
+	if err != nil {
+		return err
+	}
+	return printValue(cmd, data, dumpFormat)
+}
+
+// printValue prints the json data in format.
+func printValue(cmd *cobra.Command, data []byte, format string) error {
+	codec := encoding.GetCodec(format)
+	if codec == nil {
+		return fmt.Errorf("unknown format %s", format)
+	}
+	var v map[string]interface{}
+	if err := sonic.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	out, err := codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = cmd.OutOrStdout().Write(out)
+	if err == nil && (len(out) == 0 || out[len(out)-1] != '\n') {
+		_, err = fmt.Fprintln(cmd.OutOrStdout())
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/banbridge/common/pkg/config"
+	"github.com/banbridge/common/pkg/config/file"
+)
+
+// CmdLint represents the config lint command.
+var CmdLint = &cobra.Command{
+	Use:   "lint <path>...",
+	Short: "Check config files",
+	Long: "Decode the config files with the codecs of pkg/encoding and merge them in order, then report " +
+		"the documents that cannot be decoded, the keys redefined with another type and the " +
+		"placeholders referring to missing keys. Example: petal config lint configs/",
+	Args: cobra.MinimumNArgs(1),
+	// issues are reported as an error, the usage would hide them
+	SilenceUsage: true,
+	RunE:         runLint,
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	kvs, err := loadFiles(args)
+	if err != nil {
+		return err
+	}
+	issues := config.Lint(kvs)
+	for _, issue := range issues {
+		fmt.Fprintln(cmd.OutOrStdout(), issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("%d issues found", len(issues))
+	}
+	return nil
+}
+
+// loadFiles loads the files of paths like the file source, each path can
+// be a file, a directory or a glob pattern.
+func loadFiles(paths []string) ([]*config.KeyValue, error) {
+	var kvs []*config.KeyValue
+	for _, path := range paths {
+		loaded, err := file.NewSource(path).Load()
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, loaded...)
+	}
+	return kvs, nil
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+var schemaOutput string
+
+// CmdSchema represents the config schema command.
+var CmdSchema = &cobra.Command{
+	Use:   "schema <package> <type>",
+	Short: "Generate the JSON Schema of a config struct",
+	Long: "Generate the JSON Schema of a config struct of the current module, editors use it to " +
+		"autocomplete and check the yaml files. Example: petal config schema ./internal/conf Bootstrap -o config.schema.json",
+	Args: cobra.ExactArgs(2),
+	RunE: runSchema,
+}
+
+func init() {
+	CmdSchema.Flags().StringVarP(&schemaOutput, "output", "o", "", "output file, stdout when empty")
+}
+
+var schemaMain = template.Must(template.New("main").Parse(`package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/banbridge/common/pkg/config"
+
+	target "{{.ImportPath}}"
+)
+
+func main() {
+	s, err := config.GenerateSchema(&target.{{.Type}}{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+`))
+
+// schemaModule is the go.mod of the program, its module is named under the
+// module of the struct so the internal packages can be imported.
+var schemaModule = template.Must(template.New("go.mod").Parse(`module {{.Module}}/petalschema
+
+go {{.GoVersion}}
+
+require {{.Module}} v0.0.0-00010101000000-000000000000
+
+replace {{.Module}} => {{printf "%q" .Dir}}
+{{range .Replace}}
+replace {{.Old.Path}} {{.Old.Version}} => {{printf "%q" .New.Path}} {{.New.Version}}
+{{- end}}
+`))
+
+// schemaPackage is the package of the struct, from go list.
+type schemaPackage struct {
+	ImportPath string
+	Module     struct {
+		Path      string
+		Dir       string
+		GoVersion string
+	}
+}
+
+// moduleReplace is a replace directive, from go mod edit -json.
+type moduleReplace struct {
+	Old, New struct{ Path, Version string }
+}
+
+// runSchema builds and runs a program importing the package of the struct,
+// so the struct is read with its real tags. The program is a module in a
+// temporary directory requiring the module of the struct from its directory.
+func runSchema(cmd *cobra.Command, args []string) error {
+	pkgPath, typ := args[0], args[1]
+	out, err := exec.Command("go", "list", "-json", pkgPath).Output()
+	if err != nil {
+		return fmt.Errorf("go list %s: %w", pkgPath, err)
+	}
+	var pkg schemaPackage
+	if err := json.Unmarshal(out, &pkg); err != nil {
+		return fmt.Errorf("go list %s: %w", pkgPath, err)
+	}
+	if pkg.Module.Path == "" {
+		return fmt.Errorf("%s is not in a module", pkgPath)
+	}
+	replaces, err := moduleReplaces(pkg.Module.Dir)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "petal-schema-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	var main, mod bytes.Buffer
+	if err := schemaMain.Execute(&main, map[string]string{"ImportPath": pkg.ImportPath, "Type": typ}); err != nil {
+		return err
+	}
+	if err := schemaModule.Execute(&mod, map[string]interface{}{
+		"Module":    pkg.Module.Path,
+		"Dir":       pkg.Module.Dir,
+		"GoVersion": pkg.Module.GoVersion,
+		"Replace":   replaces,
+	}); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), main.Bytes(), 0o644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), mod.Bytes(), 0o644); err != nil {
+		return err
+	}
+	// the checksums of the dependencies are the ones of the module
+	if sum, err := os.ReadFile(filepath.Join(pkg.Module.Dir, "go.sum")); err == nil {
+		if err := os.WriteFile(filepath.Join(dir, "go.sum"), sum, 0o644); err != nil {
+			return err
+		}
+	}
+
+	run := exec.Command("go", "run", ".")
+	run.Dir = dir
+	run.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	run.Stderr = cmd.ErrOrStderr()
+	schema, err := run.Output()
+	if err != nil {
+		return fmt.Errorf("generate schema of %s.%s: %w", pkg.ImportPath, typ, err)
+	}
+	if schemaOutput == "" {
+		_, err = cmd.OutOrStdout().Write(schema)
+		return err
+	}
+	return os.WriteFile(schemaOutput, schema, 0o644)
+}
+
+// moduleReplaces returns the replace directives of the module in dir, the
+// local paths made absolute.
+func moduleReplaces(dir string) ([]moduleReplace, error) {
+	edit := exec.Command("go", "mod", "edit", "-json")
+	edit.Dir = dir
+	out, err := edit.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go mod edit -json: %w", err)
+	}
+	var mod struct{ Replace []moduleReplace }
+	if err := json.Unmarshal(out, &mod); err != nil {
+		return nil, fmt.Errorf("go mod edit -json: %w", err)
+	}
+	for i, r := range mod.Replace {
+		if r.New.Version == "" && !filepath.IsAbs(r.New.Path) {
+			mod.Replace[i].New.Path = filepath.Join(dir, r.New.Path)
+		}
+	}
+	return mod.Replace, nil
+}
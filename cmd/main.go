@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/banbridge/common/cmd/internal/config"
 	"github.com/banbridge/common/cmd/internal/proto"
 	"github.com/banbridge/common/cmd/internal/upgrade"
 )
@@ -21,6 +22,7 @@ var rootCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(proto.CmdProto)
 	rootCmd.AddCommand(upgrade.CmdUpgrade)
+	rootCmd.AddCommand(config.CmdConfig)
 }
 
 func main() {
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/banbridge/common/pkg/logs"
+)
+
+// ErrNotWatchable is returned when a Config does not support merge notifications.
+var ErrNotWatchable = errors.New("config does not support merge notifications")
+
+type mergeNotifier interface {
+	onMerge(func())
+}
+
+// AtomicOption is Atomic option.
+type AtomicOption[T any] func(*Atomic[T])
+
+// WithValidator vetoes a reloaded value, the previous value is kept if fn returns an error.
+func WithValidator[T any](fn func(T) error) AtomicOption[T] {
+	return func(a *Atomic[T]) {
+		a.validate = fn
+	}
+}
+
+// Atomic is a typed handle bound to a config key, it is decoded with Bind
+// and re-decoded after every successful merge of the underlying Config.
+type Atomic[T any] struct {
+	c        Config
+	key      string
+	value    atomic.Pointer[T]
+	validate func(T) error
+
+	mu       sync.Mutex
+	onChange []func(old, new T)
+}
+
+// NewAtomic binds key of c into a new Atomic, it fails if the initial value cannot be decoded or validated.
+func NewAtomic[T any](c Config, key string, opts ...AtomicOption[T]) (*Atomic[T], error) {
+	n, ok := c.(mergeNotifier)
+	if !ok {
+		return nil, ErrNotWatchable
+	}
+	a := &Atomic[T]{c: c, key: key}
+	for _, opt := range opts {
+		opt(a)
+	}
+	v, err := a.decode()
+	if err != nil {
+		return nil, err
+	}
+	a.value.Store(&v)
+	n.onMerge(a.reload)
+	return a, nil
+}
+
+// Load returns the current value without locking.
+func (a *Atomic[T]) Load() T {
+	return *a.value.Load()
+}
+
+// OnChange registers fn to be called when a reload changes the value.
+func (a *Atomic[T]) OnChange(fn func(old, new T)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onChange = append(a.onChange, fn)
+}
+
+func (a *Atomic[T]) decode() (T, error) {
+	v, err := Bind[T](a.c, a.key)
+	if err != nil {
+		return v, err
+	}
+	if a.validate != nil {
+		if err := a.validate(v); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+// reload decodes and stores the value under a.mu, the callbacks are called
+// after it is released so they may register callbacks or reload the config.
+func (a *Atomic[T]) reload() {
+	a.mu.Lock()
+	next, err := a.decode()
+	if err != nil {
+		a.mu.Unlock()
+		logs.Error("failed to reload config key %s, keep previous value: %v", a.key, err)
+		return
+	}
+	old := *a.value.Load()
+	if reflect.DeepEqual(old, next) {
+		a.mu.Unlock()
+		return
+	}
+	a.value.Store(&next)
+	callbacks := append([]func(old, new T){}, a.onChange...)
+	a.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(old, next)
+	}
+}
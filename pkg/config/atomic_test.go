@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// testUpdateSource emits every string sent to updates as a new json payload.
+type testUpdateSource struct {
+	data    string
+	updates chan string
+}
+
+func newTestUpdateSource(data string) *testUpdateSource {
+	return &testUpdateSource{data: data, updates: make(chan string)}
+}
+
+func (s *testUpdateSource) Load() ([]*KeyValue, error) {
+	return []*KeyValue{{Key: "json", Value: []byte(s.data), Format: "json"}}, nil
+}
+
+func (s *testUpdateSource) Watch() (Watcher, error) {
+	return &testUpdateWatcher{updates: s.updates, exit: make(chan struct{})}, nil
+}
+
+type testUpdateWatcher struct {
+	updates chan string
+	exit    chan struct{}
+}
+
+func (w *testUpdateWatcher) Next() ([]*KeyValue, error) {
+	select {
+	case data := <-w.updates:
+		return []*KeyValue{{Key: "json", Value: []byte(data), Format: "json"}}, nil
+	case <-w.exit:
+		return nil, errStopped
+	}
+}
+
+func (w *testUpdateWatcher) Stop() error {
+	close(w.exit)
+	return nil
+}
+
+var errStopped = errors.New("watcher stopped")
+
+func TestAtomic(t *testing.T) {
+	type limits struct {
+		QPS int `config:"qps" validate:"min=1"`
+	}
+	src := newTestUpdateSource(`{"limits":{"qps":10}}`)
+	c := New(WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	a, err := NewAtomic(c, "limits", WithValidator(func(l limits) error {
+		if l.QPS > 1000 {
+			return errors.New("qps too large")
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Load().QPS != 10 {
+		t.Fatalf("qps want: 10, got: %d", a.Load().QPS)
+	}
+	changed := make(chan [2]int, 1)
+	a.OnChange(func(old, new limits) {
+		changed <- [2]int{old.QPS, new.QPS}
+	})
+
+	src.updates <- `{"limits":{"qps":20}}`
+	select {
+	case got := <-changed:
+		if got != [2]int{10, 20} {
+			t.Errorf("change want: [10 20], got: %v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnChange not called")
+	}
+
+	// vetoed by the validator and by the validate tag
+	src.updates <- `{"limits":{"qps":2000}}`
+	src.updates <- `{"limits":{"qps":0}}`
+	src.updates <- `{"limits":{"qps":20}}`
+	select {
+	case got := <-changed:
+		t.Errorf("unexpected change: %v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if a.Load().QPS != 20 {
+		t.Errorf("qps want: 20, got: %d", a.Load().QPS)
+	}
+}
+
+func TestAtomicOnChangeReentrant(t *testing.T) {
+	src := newTestUpdateSource(`{"qps":10}`)
+	c := New(WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	a, err := NewAtomic[int](c, "qps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan int, 2)
+	a.OnChange(func(_, new int) {
+		// registering from a callback must not deadlock
+		a.OnChange(func(int, int) {})
+		changed <- new
+	})
+	src.updates <- `{"qps":20}`
+	select {
+	case got := <-changed:
+		if got != 20 {
+			t.Errorf("qps want: 20, got: %d", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnChange not called")
+	}
+}
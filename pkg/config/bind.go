@@ -0,0 +1,493 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// FieldError is a single key that failed to bind.
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// BindError aggregates every key that failed to bind.
+type BindError struct {
+	Errors []*FieldError
+}
+
+func (e *BindError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return fmt.Sprintf("config: %d invalid key(s): %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *BindError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// Bind decodes the value at key into a new T, key "" binds the whole config.
+//
+// Struct fields are matched by the `config` tag, then the `json` tag, then the
+// field name (case-insensitive). A `default` tag is used when the key is missing,
+// and a `validate` tag checks the decoded value, e.g.
+//
+//	Port int `config:"port" default:"8080" validate:"min=1,max=65535"`
+//
+// Supported rules are required, min, max and oneof. All failures are
+// returned together as a *BindError with the dotted path of each key.
+func Bind[T any](c Config, key string) (T, error) {
+	var t T
+	err := BindTo(c, key, &t)
+	return t, err
+}
+
+// BindTo is like Bind but decodes into the pointer dst.
+func BindTo(c Config, key string, dst interface{}) error {
+	raw, err := rawValue(c, key)
+	if err != nil {
+		return err
+	}
+	return decode(key, raw, dst)
+}
+
+func rawValue(c Config, key string) (interface{}, error) {
+	if key == "" {
+		root := make(map[string]interface{})
+		if err := c.Scan(&root); err != nil {
+			return nil, err
+		}
+		return root, nil
+	}
+	if cc, ok := c.(*config); ok {
+		// bypass the value cache, it lags behind the reader during a reload
+		if v, ok := cc.reader.Value(key); ok {
+			return v.Load(), nil
+		}
+		return nil, nil
+	}
+	return c.Value(key).Load(), nil
+}
+
+// decode binds raw into the pointer dst, path prefixes every reported key.
+func decode(path string, raw interface{}, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("config: bind target must be a non-nil pointer, got %T", dst)
+	}
+	b := &binder{}
+	raw = convertMap(raw)
+	elem := rv.Elem()
+	switch {
+	case raw != nil:
+		b.decode(path, raw, elem)
+	case indirectType(elem.Type()).Kind() == reflect.Struct:
+		b.bindStruct(path, nil, deref(elem))
+	default:
+		b.fail(path, ErrNotFound)
+	}
+	if len(b.errs) > 0 {
+		return &BindError{Errors: b.errs}
+	}
+	return nil
+}
+
+type binder struct {
+	errs []*FieldError
+}
+
+func (b *binder) fail(path string, err error) {
+	b.errs = append(b.errs, &FieldError{Path: path, Err: err})
+}
+
+func (b *binder) bindStruct(path string, values map[string]interface{}, rv reflect.Value) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := FieldName(field)
+		if name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			b.bindStruct(path, values, deref(fv))
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fpath := joinPath(path, name)
+		rules, err := parseRules(field.Tag.Get("validate"))
+		if err != nil {
+			b.fail(fpath, err)
+			continue
+		}
+		raw, ok := lookupKey(values, name)
+		if !ok {
+			if def, has := field.Tag.Lookup("default"); has {
+				raw, ok = def, true
+			}
+		}
+		if !ok {
+			if rules.required {
+				b.fail(fpath, ErrNotFound)
+			} else if indirectType(field.Type).Kind() == reflect.Struct && !implementsText(field.Type) {
+				// let nested defaults and required keys apply
+				b.bindStruct(fpath, nil, deref(fv))
+			}
+			continue
+		}
+		if !b.decode(fpath, raw, fv) {
+			continue
+		}
+		if err := rules.check(fv); err != nil {
+			b.fail(fpath, err)
+		}
+	}
+}
+
+// decode converts raw into rv and reports whether it succeeded.
+func (b *binder) decode(path string, raw interface{}, rv reflect.Value) bool {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return b.decode(path, raw, rv.Elem())
+	}
+	if s, ok := raw.(string); ok && implementsText(rv.Type()) {
+		if err := rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			b.fail(path, err)
+			return false
+		}
+		return true
+	}
+	if rv.Type() == durationType {
+		d, err := parseDuration(raw)
+		if err != nil {
+			b.fail(path, err)
+			return false
+		}
+		rv.SetInt(int64(d))
+		return true
+	}
+	av := &atomicValue{}
+	av.Store(raw)
+	switch rv.Kind() {
+	case reflect.Bool:
+		v, err := av.Bool()
+		if err != nil {
+			b.fail(path, err)
+			return false
+		}
+		rv.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := av.Int()
+		if err != nil {
+			b.fail(path, err)
+			return false
+		}
+		if rv.OverflowInt(v) {
+			b.fail(path, fmt.Errorf("value %d overflows %s", v, rv.Type()))
+			return false
+		}
+		rv.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := av.Int()
+		if err != nil {
+			b.fail(path, err)
+			return false
+		}
+		if v < 0 || rv.OverflowUint(uint64(v)) {
+			b.fail(path, fmt.Errorf("value %d overflows %s", v, rv.Type()))
+			return false
+		}
+		rv.SetUint(uint64(v))
+	case reflect.Float32, reflect.Float64:
+		v, err := av.Float()
+		if err != nil {
+			b.fail(path, err)
+			return false
+		}
+		if rv.OverflowFloat(v) {
+			b.fail(path, fmt.Errorf("value %v overflows %s", v, rv.Type()))
+			return false
+		}
+		rv.SetFloat(v)
+	case reflect.String:
+		v, err := av.String()
+		if err != nil {
+			b.fail(path, err)
+			return false
+		}
+		rv.SetString(v)
+	case reflect.Struct:
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			b.fail(path, fmt.Errorf("expected map, got %T", raw))
+			return false
+		}
+		n := len(b.errs)
+		b.bindStruct(path, m, rv)
+		return len(b.errs) == n
+	case reflect.Slice:
+		return b.decodeSlice(path, raw, rv)
+	case reflect.Map:
+		return b.decodeMap(path, raw, rv)
+	case reflect.Interface:
+		rv.Set(reflect.ValueOf(raw))
+	default:
+		b.fail(path, fmt.Errorf("unsupported type %s", rv.Type()))
+		return false
+	}
+	return true
+}
+
+func (b *binder) decodeSlice(path string, raw interface{}, rv reflect.Value) bool {
+	var items []interface{}
+	switch vt := raw.(type) {
+	case []interface{}:
+		items = vt
+	case string:
+		// comma separated list, used by default tags
+		if vt != "" {
+			for _, s := range strings.Split(vt, ",") {
+				items = append(items, strings.TrimSpace(s))
+			}
+		}
+	default:
+		b.fail(path, fmt.Errorf("expected list, got %T", raw))
+		return false
+	}
+	n := len(b.errs)
+	slice := reflect.MakeSlice(rv.Type(), len(items), len(items))
+	for i, item := range items {
+		b.decode(fmt.Sprintf("%s[%d]", path, i), item, slice.Index(i))
+	}
+	rv.Set(slice)
+	return len(b.errs) == n
+}
+
+func (b *binder) decodeMap(path string, raw interface{}, rv reflect.Value) bool {
+	if rv.Type().Key().Kind() != reflect.String {
+		b.fail(path, fmt.Errorf("unsupported map key type %s", rv.Type().Key()))
+		return false
+	}
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		b.fail(path, fmt.Errorf("expected map, got %T", raw))
+		return false
+	}
+	n := len(b.errs)
+	out := reflect.MakeMapWithSize(rv.Type(), len(m))
+	for k, v := range m {
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		if b.decode(joinPath(path, k), v, elem) {
+			out.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem)
+		}
+	}
+	rv.Set(out)
+	return len(b.errs) == n
+}
+
+// parseDuration accepts duration strings such as "1m30s" as well as
+// integers in nanoseconds like Value.Duration.
+func parseDuration(raw interface{}) (time.Duration, error) {
+	if s, ok := raw.(string); ok {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d, nil
+		}
+	}
+	av := &atomicValue{}
+	av.Store(raw)
+	return av.Duration()
+}
+
+type rules struct {
+	required bool
+	min, max *string
+	oneof    []string
+}
+
+func parseRules(tag string) (rules, error) {
+	var r rules
+	if tag == "" {
+		return r, nil
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "":
+		case "required":
+			r.required = true
+		case "min":
+			r.min = &arg
+		case "max":
+			r.max = &arg
+		case "oneof":
+			r.oneof = strings.Fields(arg)
+		default:
+			return r, fmt.Errorf("unknown validate rule %q", name)
+		}
+	}
+	return r, nil
+}
+
+func (r rules) check(rv reflect.Value) error {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if r.min != nil {
+		if err := compare(rv, *r.min, func(c int) bool { return c >= 0 }, "less than min"); err != nil {
+			return err
+		}
+	}
+	if r.max != nil {
+		if err := compare(rv, *r.max, func(c int) bool { return c <= 0 }, "greater than max"); err != nil {
+			return err
+		}
+	}
+	if len(r.oneof) > 0 {
+		s := fmt.Sprint(rv.Interface())
+		for _, o := range r.oneof {
+			if s == o {
+				return nil
+			}
+		}
+		return fmt.Errorf("value %q is not one of [%s]", s, strings.Join(r.oneof, " "))
+	}
+	return nil
+}
+
+// compare checks the value (or the length for strings, slices and maps) against bound.
+func compare(rv reflect.Value, bound string, ok func(int) bool, msg string) error {
+	var c int
+	switch {
+	case rv.Type() == durationType:
+		d, err := time.ParseDuration(bound)
+		if err != nil {
+			return fmt.Errorf("invalid bound %q: %w", bound, err)
+		}
+		c = cmpFloat(float64(rv.Int()), float64(d))
+	case rv.Kind() == reflect.String || rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map:
+		n, err := strconv.Atoi(bound)
+		if err != nil {
+			return fmt.Errorf("invalid bound %q: %w", bound, err)
+		}
+		if !ok(cmpFloat(float64(rv.Len()), float64(n))) {
+			return fmt.Errorf("length %d is %s %s", rv.Len(), msg, bound)
+		}
+		return nil
+	default:
+		f, err := strconv.ParseFloat(bound, 64)
+		if err != nil {
+			return fmt.Errorf("invalid bound %q: %w", bound, err)
+		}
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			c = cmpFloat(float64(rv.Int()), f)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			c = cmpFloat(float64(rv.Uint()), f)
+		case reflect.Float32, reflect.Float64:
+			c = cmpFloat(rv.Float(), f)
+		default:
+			return fmt.Errorf("rule %s not supported for %s", msg, rv.Type())
+		}
+	}
+	if !ok(c) {
+		return fmt.Errorf("value %v is %s %s", rv.Interface(), msg, bound)
+	}
+	return nil
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// FieldName returns the key Bind reads field from, the name of its `config`
+// or `json` tag, or "" for a field without one.
+func FieldName(field reflect.StructField) string {
+	for _, key := range []string{"config", "json"} {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			if name, _, _ := strings.Cut(tag, ","); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// lookupKey finds key in values, falling back to a case-insensitive match.
+func lookupKey(values map[string]interface{}, key string) (interface{}, bool) {
+	if v, ok := values[key]; ok && v != nil {
+		return v, true
+	}
+	for k, v := range values {
+		if strings.EqualFold(k, key) && v != nil {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func deref(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+func implementsText(t reflect.Type) bool {
+	return reflect.PointerTo(indirectType(t)).Implements(textUnmarshalerType)
+}
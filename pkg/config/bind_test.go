@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const _testBindJSON = `
+{
+    "server":{
+        "addr":"0.0.0.0",
+        "port":8000,
+        "timeout":"1m30s",
+        "idle":1000,
+        "tags":["a","b"],
+        "mode":"debug",
+        "limits":{"read":10,"write":20}
+    }
+}`
+
+type testServerConfig struct {
+	Addr    string           `config:"addr" validate:"required"`
+	Port    int              `config:"port" default:"8080" validate:"min=1,max=65535"`
+	Timeout time.Duration    `config:"timeout"`
+	Idle    time.Duration    `config:"idle"`
+	Retry   time.Duration    `config:"retry" default:"5s"`
+	Tags    []string         `config:"tags"`
+	Hosts   []string         `config:"hosts" default:"a.com, b.com"`
+	Mode    string           `config:"mode" validate:"oneof=debug release"`
+	Limits  map[string]int64 `config:"limits"`
+	TLS     struct {
+		Enable bool   `config:"enable" default:"true"`
+		Cert   string `config:"cert"`
+	} `config:"tls"`
+}
+
+func newTestBindConfig(t *testing.T, data string) Config {
+	c := New(WithSource(newTestJSONSource(data)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestBind(t *testing.T) {
+	c := newTestBindConfig(t, _testBindJSON)
+	conf, err := Bind[testServerConfig](c, "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Addr != "0.0.0.0" || conf.Port != 8000 {
+		t.Errorf("unexpected addr/port: %s %d", conf.Addr, conf.Port)
+	}
+	if conf.Timeout != 90*time.Second {
+		t.Errorf("timeout want: %v, got: %v", 90*time.Second, conf.Timeout)
+	}
+	if conf.Idle != time.Duration(1000) {
+		t.Errorf("idle want: %v, got: %v", time.Duration(1000), conf.Idle)
+	}
+	if conf.Retry != 5*time.Second {
+		t.Errorf("retry want: %v, got: %v", 5*time.Second, conf.Retry)
+	}
+	if len(conf.Tags) != 2 || conf.Tags[1] != "b" {
+		t.Errorf("unexpected tags: %v", conf.Tags)
+	}
+	if len(conf.Hosts) != 2 || conf.Hosts[1] != "b.com" {
+		t.Errorf("unexpected hosts: %v", conf.Hosts)
+	}
+	if conf.Limits["write"] != 20 {
+		t.Errorf("unexpected limits: %v", conf.Limits)
+	}
+	if !conf.TLS.Enable {
+		t.Error("tls.enable default is not applied")
+	}
+}
+
+func TestBindRoot(t *testing.T) {
+	c := newTestBindConfig(t, _testBindJSON)
+	var conf struct {
+		Server testServerConfig `config:"server"`
+	}
+	if err := BindTo(c, "", &conf); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Server.Port != 8000 {
+		t.Errorf("server.port want: 8000, got: %d", conf.Server.Port)
+	}
+}
+
+func TestBindError(t *testing.T) {
+	c := newTestBindConfig(t, `{"server":{"port":70000,"mode":"test","timeout":"soon"}}`)
+	_, err := Bind[testServerConfig](c, "server")
+	var be *BindError
+	if !errors.As(err, &be) {
+		t.Fatalf("want *BindError, got: %v", err)
+	}
+	paths := make([]string, 0, len(be.Errors))
+	for _, fe := range be.Errors {
+		paths = append(paths, fe.Path)
+	}
+	want := []string{"server.addr", "server.port", "server.timeout", "server.mode"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("paths want: %v, got: %v", want, paths)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("missing required key should wrap ErrNotFound")
+	}
+}
+
+func TestBindMissingKey(t *testing.T) {
+	c := newTestBindConfig(t, `{}`)
+	conf, err := Bind[struct {
+		Port int `config:"port" default:"8080"`
+	}](c, "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Port != 8080 {
+		t.Errorf("port want: 8080, got: %d", conf.Port)
+	}
+	if _, err = Bind[int](c, "server.port"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want ErrNotFound, got: %v", err)
+	}
+}
@@ -9,8 +9,12 @@ import (
 
 	"dario.cat/mergo"
 
+	_ "github.com/banbridge/common/pkg/encoding/hcl"
+	_ "github.com/banbridge/common/pkg/encoding/ini"
 	_ "github.com/banbridge/common/pkg/encoding/json"
+	_ "github.com/banbridge/common/pkg/encoding/properties"
 	_ "github.com/banbridge/common/pkg/encoding/proto"
+	_ "github.com/banbridge/common/pkg/encoding/toml"
 	_ "github.com/banbridge/common/pkg/encoding/xml"
 	_ "github.com/banbridge/common/pkg/encoding/yaml"
 	"github.com/banbridge/common/pkg/logs"
@@ -29,6 +33,14 @@ type Config interface {
 	Scan(v interface{}) error
 	Value(key string) Value
 	Watch(key string, o Observer) error
+	Subscribe(prefix string, fn func(ChangeSet))
+	Explain(key string) (*Explanation, error)
+	// Generation counts the successful loads and reloads.
+	Generation() uint64
+	// LastSuccess returns when the config was last loaded or reloaded successfully.
+	LastSuccess() time.Time
+	// LastError returns the error of the last load or reload, nil if it succeeded.
+	LastError() error
 	Close() error
 }
 
@@ -38,27 +50,76 @@ type config struct {
 	cached    sync.Map
 	observers sync.Map
 	watchers  []Watcher
+
+	lock        sync.Mutex
+	last        map[string]interface{}
+	listeners   []func()
+	subscribers []subscriber
+
+	generation  uint64
+	lastSuccess time.Time
+	lastError   error
+}
+
+type subscriber struct {
+	prefix string
+	fn     func(ChangeSet)
 }
 
 // New a config with options.
 func New(opts ...Option) Config {
+	o := newOptions(opts)
+	return &config{
+		opts:   o,
+		reader: newReader(o),
+	}
+}
+
+func newOptions(opts []Option) options {
 	o := options{
 		decoder:  defaultDecoder,
 		resolver: defaultResolver,
 		merge: func(dst, src interface{}) error {
 			return mergo.Map(dst, src, mergo.WithOverride)
 		},
+		secrets: newSecrets(),
 	}
 	for _, opt := range opts {
 		opt(&o)
 	}
-	return &config{
-		opts:   o,
-		reader: newReader(o),
+	return o
+}
+
+// merge merges kvs loaded from src, keeping track of their layer when the reader supports it.
+func (c *config) merge(src *layeredSource, kvs []*KeyValue) error {
+	if r, ok := c.reader.(*reader); ok {
+		return r.mergeFrom(src, kvs...)
+	}
+	return c.reader.Merge(kvs...)
+}
+
+// reload merges, resolves and validates kvs loaded from src by a watcher
+// into a staging map that replaces the current config only if every step
+// succeeded, a failed reload keeps the last good config.
+func (c *config) reload(src *layeredSource, kvs []*KeyValue) error {
+	if r, ok := c.reader.(*reader); ok {
+		return r.update(src, kvs, c.validate)
+	}
+	if err := c.reader.Merge(kvs...); err != nil {
+		return err
+	}
+	return c.reader.Resolve()
+}
+
+// validate checks the merged config against the schema of WithSchema.
+func (c *config) validate(values map[string]interface{}) error {
+	if c.opts.schema == nil {
+		return nil
 	}
+	return c.opts.schema.Validate(values)
 }
 
-func (c *config) watch(w Watcher) {
+func (c *config) watch(src *layeredSource, w Watcher) {
 	for {
 		kvs, err := w.Next()
 		if err != nil {
@@ -70,14 +131,16 @@ func (c *config) watch(w Watcher) {
 			logs.Error("failed to watch next config: %v", err)
 			continue
 		}
-		if err := c.reader.Merge(kvs...); err != nil {
-			logs.Error("failed to merge next config: %v", err)
+		if err := c.reload(src, kvs); err != nil {
+			c.record(err)
+			logs.Error("failed to reload next config, keep the previous one: %v", err)
 			continue
 		}
-		if err := c.reader.Resolve(); err != nil {
-			logs.Error("failed to resolve next config: %v", err)
-			continue
+		c.record(nil)
+		if cm, ok := w.(Committer); ok {
+			cm.Commit(kvs)
 		}
+		c.publish()
 		c.cached.Range(func(key, value interface{}) bool {
 			k := key.(string)
 			v := value.(Value)
@@ -92,16 +155,17 @@ func (c *config) watch(w Watcher) {
 	}
 }
 
-func (c *config) Load() error {
-	for _, src := range c.opts.sources {
+func (c *config) Load() (err error) {
+	defer func() { c.record(err) }()
+	for _, src := range c.opts.layeredSources() {
 		kvs, err := src.Load()
 		if err != nil {
 			return err
 		}
 		for _, v := range kvs {
-			logs.Debug("config loaded: %s format: %s", v.Key, v.Format)
+			logs.Debug("config loaded: %s format: %s source: %s layer: %s", v.Key, v.Format, src.name, src.layer)
 		}
-		if err = c.reader.Merge(kvs...); err != nil {
+		if err = c.merge(src, kvs); err != nil {
 			logs.Error("failed to merge config source: %v", err)
 			return err
 		}
@@ -111,15 +175,50 @@ func (c *config) Load() error {
 			return err
 		}
 		c.watchers = append(c.watchers, w)
-		go c.watch(w)
+		go c.watch(src, w)
+	}
+	if r, ok := c.reader.(*reader); ok {
+		err = r.resolve(c.validate)
+	} else {
+		err = c.reader.Resolve()
 	}
-	if err := c.reader.Resolve(); err != nil {
+	if err != nil {
 		logs.Error("failed to resolve config source: %v", err)
 		return err
 	}
+	c.publish()
 	return nil
 }
 
+// record updates the reload status with the result of a load or reload.
+func (c *config) record(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lastError = err
+	if err == nil {
+		c.generation++
+		c.lastSuccess = time.Now()
+	}
+}
+
+func (c *config) Generation() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.generation
+}
+
+func (c *config) LastSuccess() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lastSuccess
+}
+
+func (c *config) LastError() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lastError
+}
+
 func (c *config) Value(key string) Value {
 	if v, ok := c.cached.Load(key); ok {
 		return v.(Value)
@@ -132,13 +231,31 @@ func (c *config) Value(key string) Value {
 }
 
 func (c *config) Scan(v interface{}) error {
-	data, err := c.reader.Source()
+	var (
+		data []byte
+		err  error
+	)
+	if r, ok := c.reader.(*reader); ok {
+		// Source redacts the secrets the scanned struct needs
+		data, err = r.source()
+	} else {
+		data, err = c.reader.Source()
+	}
 	if err != nil {
 		return err
 	}
 	return unmarshalJSON(data, v)
 }
 
+// Dump returns the merged config of c as json, with the resolved secrets redacted.
+func Dump(c Config) ([]byte, error) {
+	cc, ok := c.(*config)
+	if !ok {
+		return nil, errors.New("config: Dump needs a Config created by New")
+	}
+	return cc.reader.Source()
+}
+
 func (c *config) Watch(key string, o Observer) error {
 	if v := c.Value(key); v.Load() == nil {
 		return ErrNotFound
@@ -147,6 +264,46 @@ func (c *config) Watch(key string, o Observer) error {
 	return nil
 }
 
+// Subscribe registers fn to receive the changes under prefix after every merge,
+// an empty prefix subscribes to all keys.
+func (c *config) Subscribe(prefix string, fn func(ChangeSet)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.subscribers = append(c.subscribers, subscriber{prefix: prefix, fn: fn})
+}
+
+// onMerge registers fn to be called after every successful merge and resolve.
+func (c *config) onMerge(fn func()) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// publish diffs the merged map against the previous one and notifies
+// merge listeners and the subscribers of the changed keys.
+func (c *config) publish() {
+	c.lock.Lock()
+	var changes ChangeSet
+	if r, ok := c.reader.(*reader); ok {
+		next := r.snapshot()
+		changes = diff(c.last, next)
+		c.last = next
+	}
+	listeners, subscribers := c.listeners, c.subscribers
+	c.lock.Unlock()
+	for _, fn := range listeners {
+		fn()
+	}
+	if len(changes) == 0 {
+		return
+	}
+	for _, s := range subscribers {
+		if cs := changes.Filter(s.prefix); len(cs) > 0 {
+			s.fn(cs)
+		}
+	}
+}
+
 func (c *config) Close() error {
 	for _, w := range c.watchers {
 		if err := w.Stop(); err != nil {
@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"dario.cat/mergo"
 )
@@ -187,3 +188,60 @@ func TestConfig(t *testing.T) {
 		t.Error("len(testConf.Endpoints) is not equal to 2")
 	}
 }
+
+func TestReloadRollback(t *testing.T) {
+	source := newTestUpdateSource(`{"db":{"host":"a","port":1}}`)
+	c := New(WithSource(source))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c.Generation() != 1 || c.LastError() != nil || c.LastSuccess().IsZero() {
+		t.Fatalf("unexpected status after load: %d %v %v", c.Generation(), c.LastError(), c.LastSuccess())
+	}
+	loaded, generation := c.LastSuccess(), c.Generation()
+
+	waitError := func() error {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if err := c.LastError(); err != nil {
+				return err
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("reload did not fail")
+		return nil
+	}
+	for _, bad := range []string{
+		`{"db":`,
+		// decodes and merges but fails to resolve
+		`{"db":{"host":"b","port":2,"password":"${db.secret:?db.secret must be set}"}}`,
+	} {
+		source.updates <- bad
+		if err := waitError(); err == nil {
+			t.Fatal("want a reload error")
+		}
+		if got, _ := c.Value("db.host").String(); got != "a" {
+			t.Errorf("db.host = %q, want the last good value a", got)
+		}
+		if v, ok := c.(*config).reader.Value("db.port"); !ok || v.Load() != float64(1) {
+			t.Errorf("db.port = %v, want the last good value 1", v)
+		}
+		if c.Generation() != generation || !c.LastSuccess().Equal(loaded) {
+			t.Errorf("failed reload changed the status: %d %v", c.Generation(), c.LastSuccess())
+		}
+		// the next good reload clears the error
+		source.updates <- `{"db":{"host":"a","port":1}}`
+		deadline := time.Now().Add(5 * time.Second)
+		for c.LastError() != nil && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if c.LastError() != nil {
+			t.Fatalf("LastError() = %v after a good reload", c.LastError())
+		}
+		loaded, generation = c.LastSuccess(), c.Generation()
+	}
+	if c.Generation() != 3 {
+		t.Errorf("Generation() = %d, want 3", c.Generation())
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeType is the kind of change made to a config key.
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+)
+
+// Change is a single changed key, Old is nil when added and New is nil when removed.
+type Change struct {
+	Path string
+	Type ChangeType
+	Old  interface{}
+	New  interface{}
+}
+
+// ChangeSet is the list of changes made by a merge, sorted by path.
+type ChangeSet []Change
+
+// Filter returns the changes under prefix, an empty prefix matches all keys.
+func (cs ChangeSet) Filter(prefix string) ChangeSet {
+	if prefix == "" {
+		return cs
+	}
+	var out ChangeSet
+	for _, c := range cs {
+		if hasPathPrefix(c.Path, prefix) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Paths returns the path of each change.
+func (cs ChangeSet) Paths() []string {
+	paths := make([]string, 0, len(cs))
+	for _, c := range cs {
+		paths = append(paths, c.Path)
+	}
+	return paths
+}
+
+// hasPathPrefix reports whether path is prefix or a key nested under it.
+func hasPathPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+".")
+}
+
+// Diff compares two config trees, such as two decoded documents, and
+// returns the changes from prev to next.
+func Diff(prev, next map[string]interface{}) ChangeSet {
+	return diff(convertMap(prev).(map[string]interface{}), convertMap(next).(map[string]interface{}))
+}
+
+// diff compares two merged maps, nested maps are walked and any other
+// value (including lists) is compared as a whole.
+func diff(prev, next map[string]interface{}) ChangeSet {
+	var cs ChangeSet
+	diffMap("", prev, next, &cs)
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Path < cs[j].Path })
+	return cs
+}
+
+func diffMap(prefix string, prev, next map[string]interface{}, cs *ChangeSet) {
+	for k, pv := range prev {
+		path := joinPath(prefix, k)
+		nv, ok := next[k]
+		if !ok {
+			*cs = append(*cs, Change{Path: path, Type: ChangeRemoved, Old: pv})
+			continue
+		}
+		pm, pok := pv.(map[string]interface{})
+		nm, nok := nv.(map[string]interface{})
+		if pok && nok {
+			diffMap(path, pm, nm, cs)
+			continue
+		}
+		if !reflect.DeepEqual(pv, nv) {
+			*cs = append(*cs, Change{Path: path, Type: ChangeModified, Old: pv, New: nv})
+		}
+	}
+	for k, nv := range next {
+		if _, ok := prev[k]; !ok {
+			*cs = append(*cs, Change{Path: joinPath(prefix, k), Type: ChangeAdded, New: nv})
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	prev := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host": "127.0.0.1",
+			"port": 3306,
+		},
+		"endpoints": []interface{}{"a", "b"},
+		"debug":     true,
+	}
+	next := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host": "10.0.0.1",
+			"user": "root",
+		},
+		"endpoints": []interface{}{"a", "b"},
+		"debug":     map[string]interface{}{"level": "info"},
+	}
+	want := ChangeSet{
+		{Path: "db.host", Type: ChangeModified, Old: "127.0.0.1", New: "10.0.0.1"},
+		{Path: "db.port", Type: ChangeRemoved, Old: 3306},
+		{Path: "db.user", Type: ChangeAdded, New: "root"},
+		{Path: "debug", Type: ChangeModified, Old: true, New: map[string]interface{}{"level": "info"}},
+	}
+	if got := diff(prev, next); !reflect.DeepEqual(got, want) {
+		t.Errorf("diff want: %+v, got: %+v", want, got)
+	}
+	if got := diff(prev, next).Filter("db").Paths(); !reflect.DeepEqual(got, []string{"db.host", "db.port", "db.user"}) {
+		t.Errorf("unexpected filtered paths: %v", got)
+	}
+	if got := diff(prev, next).Filter("d"); len(got) != 0 {
+		t.Errorf("prefix must match whole keys, got: %v", got)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	src := newTestUpdateSource(`{"db":{"host":"127.0.0.1"},"log":{"level":"info"}}`)
+	c := New(WithSource(src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	changes := make(chan ChangeSet, 1)
+	c.Subscribe("db", func(cs ChangeSet) {
+		changes <- cs
+	})
+
+	src.updates <- `{"log":{"level":"debug"}}`
+	src.updates <- `{"db":{"host":"10.0.0.1"}}`
+	select {
+	case cs := <-changes:
+		want := ChangeSet{{Path: "db.host", Type: ChangeModified, Old: "127.0.0.1", New: "10.0.0.1"}}
+		if !reflect.DeepEqual(cs, want) {
+			t.Errorf("changes want: %+v, got: %+v", want, cs)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscriber not called")
+	}
+}
@@ -0,0 +1,214 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadDotEnv reads and parses a .env file, see ParseDotEnv.
+func ReadDotEnv(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := ParseDotEnv(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// ParseDotEnv parses .env content of KEY=VALUE lines.
+//
+// Lines may start with "export ", "#" starts a comment. Single quoted values
+// are taken literally, double quoted values may span lines and support
+// \n, \r, \t, \", \\ and \$ escapes. Unquoted and double quoted values expand
+// $VAR, ${VAR} and ${VAR:-default} from the variables defined above them,
+// then from the process environment.
+func ParseDotEnv(data []byte) (map[string]string, error) {
+	vars := make(map[string]string)
+	lookup := func(key string) (string, bool) {
+		if v, ok := vars[key]; ok {
+			return v, true
+		}
+		return os.LookupEnv(key)
+	}
+	p := &dotenvParser{src: strings.ReplaceAll(string(data), "\r\n", "\n"), line: 1}
+	for {
+		key, value, ok, err := p.next(lookup)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+		if !ok {
+			return vars, nil
+		}
+		vars[key] = value
+	}
+}
+
+type dotenvParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *dotenvParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *dotenvParser) advance() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+func (p *dotenvParser) skipLine() {
+	for p.pos < len(p.src) && p.peek() != '\n' {
+		p.advance()
+	}
+}
+
+func (p *dotenvParser) skipBlank() {
+	for p.pos < len(p.src) && (p.peek() == ' ' || p.peek() == '\t') {
+		p.advance()
+	}
+}
+
+// next returns the next assignment, ok is false at the end of input.
+func (p *dotenvParser) next(lookup func(string) (string, bool)) (key, value string, ok bool, err error) {
+	for p.pos < len(p.src) {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\n':
+			p.advance()
+			continue
+		case c == '#':
+			p.skipLine()
+			continue
+		}
+		break
+	}
+	if p.pos >= len(p.src) {
+		return "", "", false, nil
+	}
+	if strings.HasPrefix(p.src[p.pos:], "export ") || strings.HasPrefix(p.src[p.pos:], "export\t") {
+		p.pos += len("export")
+		p.skipBlank()
+	}
+	start := p.pos
+	for p.pos < len(p.src) && isKeyChar(p.peek()) {
+		p.advance()
+	}
+	key = p.src[start:p.pos]
+	if key == "" {
+		return "", "", false, fmt.Errorf("invalid character %q in key", p.peek())
+	}
+	p.skipBlank()
+	if p.peek() != '=' {
+		return "", "", false, fmt.Errorf("expected '=' after %s", key)
+	}
+	p.advance()
+	p.skipBlank()
+
+	switch p.peek() {
+	case '\'':
+		p.advance()
+		start = p.pos
+		for p.pos < len(p.src) && p.peek() != '\'' {
+			p.advance()
+		}
+		if p.pos >= len(p.src) {
+			return "", "", false, fmt.Errorf("unterminated single quote in %s", key)
+		}
+		value = p.src[start:p.pos]
+		p.advance()
+	case '"':
+		p.advance()
+		start = p.pos
+		for p.pos < len(p.src) && p.peek() != '"' {
+			if p.advance() == '\\' && p.pos < len(p.src) {
+				p.advance()
+			}
+		}
+		if p.pos >= len(p.src) {
+			return "", "", false, fmt.Errorf("unterminated double quote in %s", key)
+		}
+		value = interpolate(p.src[start:p.pos], lookup, true)
+		p.advance()
+	default:
+		start = p.pos
+		p.skipLine()
+		raw := p.src[start:p.pos]
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = raw[:i]
+		}
+		return key, interpolate(strings.TrimSpace(raw), lookup, false), true, nil
+	}
+	// only a comment may follow a quoted value
+	p.skipBlank()
+	if c := p.peek(); c != 0 && c != '\n' && c != '#' {
+		return "", "", false, fmt.Errorf("unexpected character %q after quoted value of %s", c, key)
+	}
+	p.skipLine()
+	return key, value, true, nil
+}
+
+func isKeyChar(c byte) bool {
+	return c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// interpolate expands $VAR, ${VAR} and ${VAR:-default} in s, escapes
+// enables backslash escape sequences.
+func interpolate(s string, lookup func(string) (string, bool), escapes bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escapes && c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		case c == '$' && i+1 < len(s) && s[i+1] == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				b.WriteString(s[i:])
+				return b.String()
+			}
+			name, def, hasDef := strings.Cut(s[i+2:i+end], ":-")
+			if v, ok := lookup(name); ok && (v != "" || !hasDef) {
+				b.WriteString(v)
+			} else {
+				b.WriteString(def)
+			}
+			i += end
+		case c == '$' && i+1 < len(s) && isKeyChar(s[i+1]) && s[i+1] != '.':
+			j := i + 1
+			for j < len(s) && isKeyChar(s[j]) && s[j] != '.' {
+				j++
+			}
+			v, _ := lookup(s[i+1 : j])
+			b.WriteString(v)
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
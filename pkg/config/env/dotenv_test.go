@@ -0,0 +1,71 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	t.Setenv("DOTENV_HOST", "example.com")
+	data := `
+# comment
+export APP_NAME=petal
+APP_PORT = 8080 # inline comment
+APP_URL=http://${DOTENV_HOST}:$APP_PORT/api
+APP_LITERAL='${DOTENV_HOST} # not a comment'
+APP_QUOTED="line1\nline2 \"q\" \$HOME"
+APP_MULTI="a
+b"
+APP_DEFAULT=${NOT_SET_IN_DOTENV:-fallback}
+APP_EMPTY=
+`
+	got, err := ParseDotEnv([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"APP_NAME":    "petal",
+		"APP_PORT":    "8080",
+		"APP_URL":     "http://example.com:8080/api",
+		"APP_LITERAL": "${DOTENV_HOST} # not a comment",
+		"APP_QUOTED":  "line1\nline2 \"q\" $HOME",
+		"APP_MULTI":   "a\nb",
+		"APP_DEFAULT": "fallback",
+		"APP_EMPTY":   "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDotEnv() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseDotEnvError(t *testing.T) {
+	for _, data := range []string{
+		"APP_NAME",
+		"APP_NAME='petal",
+		`APP_NAME="petal`,
+		`APP_NAME="petal" trailing`,
+		"-APP=1",
+	} {
+		if _, err := ParseDotEnv([]byte(data)); err == nil {
+			t.Errorf("ParseDotEnv(%q) expect error", data)
+		}
+	}
+}
+
+func TestDotEnvSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("DOTENV_APP_NAME=from_file\nDOTENV_APP_PORT=80\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOTENV_APP_PORT", "9000")
+	kvs, err := New(WithPrefixes("DOTENV_"), WithDotEnv(path)).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := snapshot(kvs)
+	if string(got["APP_NAME"].Value) != "from_file" || string(got["APP_PORT"].Value) != "9000" {
+		t.Errorf("unexpected env: %v", got)
+	}
+}
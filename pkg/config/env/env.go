@@ -2,21 +2,71 @@ package env
 
 import (
 	"os"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
 
 	"github.com/banbridge/common/pkg/config"
 )
 
 type env struct {
 	prefixes []string
+	dotenv   []string
+	interval time.Duration
+
+	separator  string
+	lowerCase  bool
+	aliases    map[string]string
+	jsonValues bool
+	csvValues  bool
 }
 
+// NewSource new an env source that loads the variables with one of prefixes.
 func NewSource(prefixes ...string) config.Source {
-	return &env{prefixes: prefixes}
+	return New(WithPrefixes(prefixes...))
+}
+
+// New new an env source with options.
+func New(opts ...Option) config.Source {
+	e := &env{}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
 func (e *env) Load() (kv []*config.KeyValue, err error) {
-	return e.load(os.Environ()), nil
+	envs, err := e.environ()
+	if err != nil {
+		return nil, err
+	}
+	return e.load(envs), nil
+}
+
+// environ returns the .env variables followed by os.Environ(), so the
+// process environment wins when a key is defined in both.
+func (e *env) environ() ([]string, error) {
+	if len(e.dotenv) == 0 {
+		return os.Environ(), nil
+	}
+	vars := make(map[string]string)
+	for _, path := range e.dotenv {
+		m, err := ReadDotEnv(path)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range m {
+			vars[k] = v
+		}
+	}
+	envs := make([]string, 0, len(vars))
+	for k, v := range vars {
+		envs = append(envs, k+"="+v)
+	}
+	sort.Strings(envs)
+	return append(envs, os.Environ()...), nil
 }
 
 func (e *env) load(envs []string) []*config.KeyValue {
@@ -29,27 +79,73 @@ func (e *env) load(envs []string) []*config.KeyValue {
 			v = subs[1]
 		}
 
-		if len(e.prefixes) > 0 {
-			p, ok := matchPrefix(e.prefixes, k)
-			if !ok || len(p) == len(k) {
-				continue
+		if alias, ok := e.aliases[k]; ok {
+			k = alias
+		} else {
+			if len(e.prefixes) > 0 {
+				p, ok := matchPrefix(e.prefixes, k)
+				if !ok || len(p) == len(k) {
+					continue
+				}
+				// trim prefix
+				k = strings.TrimPrefix(k, p)
+				k = strings.TrimPrefix(k, "_")
 			}
-			// trim prefix
-			k = strings.TrimPrefix(k, p)
-			k = strings.TrimPrefix(k, "_")
+			k = e.mapKey(k)
 		}
 
 		if len(k) != 0 {
-			kv = append(kv, &config.KeyValue{
-				Key:   k,
-				Value: []byte(v),
-			})
+			kv = append(kv, e.keyValue(k, v))
 		}
 	}
 	return kv
 }
 
+// mapKey turns the separator into nested keys and applies lower-casing.
+func (e *env) mapKey(k string) string {
+	if e.separator != "" {
+		k = strings.ReplaceAll(k, e.separator, ".")
+	}
+	if e.lowerCase {
+		k = strings.ToLower(k)
+	}
+	return k
+}
+
+// keyValue builds the KeyValue of k, values parsed as lists or objects
+// are encoded as a nested json document.
+func (e *env) keyValue(k, v string) *config.KeyValue {
+	if parsed, ok := e.parseValue(v); ok {
+		if data, err := sonic.Marshal(config.Nest(k, parsed)); err == nil {
+			return &config.KeyValue{Key: k, Value: data, Format: "json"}
+		}
+	}
+	return &config.KeyValue{Key: k, Value: []byte(v)}
+}
+
+func (e *env) parseValue(v string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(v)
+	if e.jsonValues && (strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{")) {
+		var parsed interface{}
+		if err := sonic.UnmarshalString(trimmed, &parsed); err == nil {
+			return parsed, true
+		}
+	}
+	if e.csvValues && strings.Contains(v, ",") {
+		items := strings.Split(v, ",")
+		list := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			list = append(list, strings.TrimSpace(item))
+		}
+		return list, true
+	}
+	return nil, false
+}
+
 func (e *env) Watch() (config.Watcher, error) {
+	if e.interval > 0 {
+		return newPollWatcher(e)
+	}
 	w, err := NewWatcher()
 	if err != nil {
 		return nil, err
@@ -427,3 +427,58 @@ func Test_env_watch(t *testing.T) {
 	}
 	_ = w.Stop()
 }
+
+func Test_env_mapping(t *testing.T) {
+	e := New(
+		WithPrefixes("APP_"),
+		WithSeparator("__"),
+		WithLowerCase(),
+		WithAlias("DATABASE_URL", "db.dsn"),
+		WithJSONValues(),
+		WithCSVValues(),
+	).(*env)
+	got := e.load([]string{
+		"APP_DB__MAX_CONNS=20",
+		"APP_HOSTS=a, b,c",
+		`APP_LIMITS={"qps":10}`,
+		"DATABASE_URL=mysql://localhost",
+		"OTHER=1",
+	})
+	want := []*config.KeyValue{
+		{Key: "db.max_conns", Value: []byte("20")},
+		{Key: "hosts", Value: []byte(`{"hosts":["a","b","c"]}`), Format: "json"},
+		{Key: "limits", Value: []byte(`{"limits":{"qps":10}}`), Format: "json"},
+		{Key: "db.dsn", Value: []byte("mysql://localhost")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("env.load() = %v, want %v", got, want)
+	}
+}
+
+func TestEnvOverrideNested(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.yaml")
+	if err := os.WriteFile(path, []byte("db:\n  max_conns: 10\n  host: localhost\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NESTED_DB__MAX_CONNS", "20")
+	t.Setenv("NESTED_DB__REPLICAS", "r1,r2")
+	c := config.New(
+		config.WithLayer(config.LayerFile, "file", file.NewSource(path)),
+		config.WithLayer(config.LayerEnv, "env", New(WithPrefixes("NESTED_"), WithSeparator("__"), WithLowerCase(), WithCSVValues())),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var db struct {
+		MaxConns int      `config:"max_conns"`
+		Host     string   `config:"host"`
+		Replicas []string `config:"replicas"`
+	}
+	if err := config.BindTo(c, "db", &db); err != nil {
+		t.Fatal(err)
+	}
+	if db.MaxConns != 20 || db.Host != "localhost" || !reflect.DeepEqual(db.Replicas, []string{"r1", "r2"}) {
+		t.Errorf("unexpected db config: %+v", db)
+	}
+}
@@ -0,0 +1,68 @@
+package env
+
+import "time"
+
+// Option is env source option.
+type Option func(*env)
+
+// WithPrefixes only loads the variables with one of prefixes, the prefix is trimmed from the key.
+func WithPrefixes(prefixes ...string) Option {
+	return func(e *env) {
+		e.prefixes = prefixes
+	}
+}
+
+// WithPollInterval re-reads the environment every interval and emits the
+// changed keys from the watcher, 0 disables polling.
+func WithPollInterval(interval time.Duration) Option {
+	return func(e *env) {
+		e.interval = interval
+	}
+}
+
+// WithDotEnv loads variables from .env files, later files override earlier
+// ones and the process environment overrides all of them.
+func WithDotEnv(paths ...string) Option {
+	return func(e *env) {
+		e.dotenv = append(e.dotenv, paths...)
+	}
+}
+
+// WithSeparator maps separator in a variable name to a nested key,
+// with "__" DB__MAX_CONNS is loaded as DB.MAX_CONNS.
+func WithSeparator(separator string) Option {
+	return func(e *env) {
+		e.separator = separator
+	}
+}
+
+// WithLowerCase lower-cases the keys, so DB__MAX_CONNS lines up with db.max_conns.
+func WithLowerCase() Option {
+	return func(e *env) {
+		e.lowerCase = true
+	}
+}
+
+// WithAlias loads the variable name as key, it ignores prefixes and key mapping.
+func WithAlias(name, key string) Option {
+	return func(e *env) {
+		if e.aliases == nil {
+			e.aliases = make(map[string]string)
+		}
+		e.aliases[name] = key
+	}
+}
+
+// WithJSONValues parses values that are json arrays or objects.
+func WithJSONValues() Option {
+	return func(e *env) {
+		e.jsonValues = true
+	}
+}
+
+// WithCSVValues parses values containing a comma as a list, APP_HOSTS=a,b,c is loaded as [a b c].
+func WithCSVValues() Option {
+	return func(e *env) {
+		e.csvValues = true
+	}
+}
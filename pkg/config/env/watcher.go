@@ -1,7 +1,10 @@
 package env
 
 import (
+	"bytes"
 	"context"
+	"sort"
+	"time"
 
 	"github.com/banbridge/common/pkg/config"
 )
@@ -28,3 +31,78 @@ func (w *watcher) Stop() error {
 	w.cancel()
 	return nil
 }
+
+var _ config.Watcher = (*pollWatcher)(nil)
+
+// pollWatcher re-reads the environment periodically and emits the changed keys.
+type pollWatcher struct {
+	e      *env
+	last   map[string]*config.KeyValue
+	ticker *time.Ticker
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newPollWatcher(e *env) (config.Watcher, error) {
+	kvs, err := e.Load()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &pollWatcher{
+		e:      e,
+		last:   snapshot(kvs),
+		ticker: time.NewTicker(e.interval),
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// Next blocks until a poll finds changed keys or the Stop method is called,
+// removed keys are emitted with Deleted set.
+func (w *pollWatcher) Next() ([]*config.KeyValue, error) {
+	for {
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case <-w.ticker.C:
+		}
+		kvs, err := w.e.Load()
+		if err != nil {
+			return nil, err
+		}
+		next := snapshot(kvs)
+		var changed []*config.KeyValue
+		for k, kv := range next {
+			if old, ok := w.last[k]; !ok || !bytes.Equal(old.Value, kv.Value) || old.Format != kv.Format {
+				changed = append(changed, kv)
+			}
+		}
+		for k := range w.last {
+			if _, ok := next[k]; !ok {
+				changed = append(changed, &config.KeyValue{Key: k, Deleted: true})
+			}
+		}
+		w.last = next
+		if len(changed) > 0 {
+			sort.Slice(changed, func(i, j int) bool { return changed[i].Key < changed[j].Key })
+			return changed, nil
+		}
+	}
+}
+
+func (w *pollWatcher) Stop() error {
+	w.cancel()
+	w.ticker.Stop()
+	return nil
+}
+
+// snapshot indexes kvs by key, the last one wins like it does in merging.
+func snapshot(kvs []*config.KeyValue) map[string]*config.KeyValue {
+	m := make(map[string]*config.KeyValue, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv
+	}
+	return m
+}
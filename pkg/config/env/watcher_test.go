@@ -1,7 +1,12 @@
 package env
 
 import (
+	"os"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/banbridge/common/pkg/config"
 )
 
 func Test_watcher_next(t *testing.T) {
@@ -30,3 +35,48 @@ func Test_watcher_stop(t *testing.T) {
 		_ = w.Stop()
 	})
 }
+
+func Test_pollWatcher_next(t *testing.T) {
+	t.Setenv("POLL_APP_NAME", "petal")
+	t.Setenv("POLL_APP_PORT", "8080")
+	w, err := New(WithPrefixes("POLL_"), WithPollInterval(10*time.Millisecond)).Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	os.Setenv("POLL_APP_PORT", "9000")
+	os.Unsetenv("POLL_APP_NAME")
+	kvs, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*config.KeyValue{
+		{Key: "APP_NAME", Deleted: true},
+		{Key: "APP_PORT", Value: []byte("9000")},
+	}
+	if !reflect.DeepEqual(kvs, want) {
+		t.Errorf("Next() = %v, want %v", kvs, want)
+	}
+}
+
+func TestPollSource(t *testing.T) {
+	t.Setenv("POLL_APP_PORT", "8080")
+	c := config.New(config.WithSource(New(WithPrefixes("POLL_"), WithPollInterval(10*time.Millisecond))))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	changes := make(chan config.ChangeSet, 1)
+	c.Subscribe("APP_PORT", func(cs config.ChangeSet) { changes <- cs })
+
+	os.Setenv("POLL_APP_PORT", "9000")
+	select {
+	case cs := <-changes:
+		if cs[0].New != "9000" {
+			t.Errorf("APP_PORT want: 9000, got: %v", cs[0].New)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("env change not merged")
+	}
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Definition is a value defined for a key by one source.
+type Definition struct {
+	Source string
+	Layer  Layer
+	// Key is the KeyValue.Key the value was decoded from, e.g. the file name.
+	Key string
+	// Line is the line of the key in Key, 0 if the format has no positions.
+	Line  int
+	Value interface{}
+}
+
+// Explanation reports where the value of a key comes from.
+type Explanation struct {
+	Key string
+	// Value is the merged and resolved value, with the resolved secrets redacted.
+	Value interface{}
+	// Definitions holds the winning definition first, then the overridden ones.
+	Definitions []Definition
+}
+
+// Winner returns the definition that provides the value.
+func (e *Explanation) Winner() (Definition, bool) {
+	if len(e.Definitions) == 0 {
+		return Definition{}, false
+	}
+	return e.Definitions[0], true
+}
+
+// Explain reports which source provides the value of key and which lower layer values it overrides.
+func (c *config) Explain(key string) (*Explanation, error) {
+	v, ok := c.reader.Value(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	e := &Explanation{Key: key, Value: v.Load()}
+	if r, ok := c.reader.(*reader); ok {
+		r.lock.Lock()
+		redacted := r.redacted().(map[string]interface{})
+		r.lock.Unlock()
+		if rv, ok := readValue(redacted, key); ok {
+			e.Value = rv.Load()
+		}
+		e.Definitions = r.definitions(key)
+	}
+	return e, nil
+}
+
+func (r *reader) definitions(key string) []Definition {
+	r.lock.Lock()
+	entries := r.entries
+	r.lock.Unlock()
+	var defs []Definition
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		v, ok := readValue(e.values, key)
+		if !ok {
+			continue
+		}
+		defs = append(defs, Definition{
+			Source: e.src.name,
+			Layer:  e.src.layer,
+			Key:    e.kv.Key,
+			Line:   lineOf(e.kv, key),
+			Value:  v.Load(),
+		})
+	}
+	return defs
+}
+
+// lineOf finds the line of path in a yaml or json document, the keys of
+// the namespace of kv are not in the document.
+func lineOf(kv *KeyValue, path string) int {
+	switch kv.Format {
+	case "yaml", "yml", "json":
+	default:
+		return 0
+	}
+	keys := strings.Split(path, ".")
+	if len(keys) <= len(kv.Namespace) || !slices.Equal(keys[:len(kv.Namespace)], kv.Namespace) {
+		return 0
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(kv.Value, &root); err != nil || len(root.Content) == 0 {
+		return 0
+	}
+	node, line := root.Content[0], 0
+	for _, key := range keys[len(kv.Namespace):] {
+		if node.Kind != yaml.MappingNode {
+			return 0
+		}
+		found := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				line, node, found = node.Content[i].Line, node.Content[i+1], true
+				break
+			}
+		}
+		if !found {
+			return 0
+		}
+	}
+	return line
+}
@@ -0,0 +1,150 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/banbridge/common/pkg/config"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func keys(kvs []*config.KeyValue) []string {
+	ks := make([]string, 0, len(kvs))
+	for _, kv := range kvs {
+		ks = append(ks, kv.Key)
+	}
+	return ks
+}
+
+func TestLoadGlob(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"conf.d/b.yaml":      "b: 1",
+		"conf.d/a.json":      `{"a":1}`,
+		"conf.d/c.yml":       "c: 1",
+		"conf.d/notes.txt":   "unknown format",
+		"conf.d/.hide.yaml":  "hidden: true",
+		"conf.d/sub/d.yaml":  "d: 1",
+		"other/ignored.yaml": "ignored: true",
+	})
+	kvs, err := NewSource(filepath.Join(root, "conf.d", "*.*")).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := keys(kvs), []string{"a.json", "b.yaml", "c.yml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("keys want: %v, got: %v", want, got)
+	}
+	if kvs[2].Format != "yaml" {
+		t.Errorf("c.yml format want: yaml, got: %s", kvs[2].Format)
+	}
+
+	kvs, err = NewSource(filepath.Join(root, "*", "*.yaml")).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := keys(kvs), []string{"conf.d/b.yaml", "other/ignored.yaml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("keys want: %v, got: %v", want, got)
+	}
+}
+
+func TestLoadRecursiveNamespace(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "conf.d")
+	writeFiles(t, dir, map[string]string{"tls.json": `{"cert":"c1"}`})
+	writeFiles(t, root, map[string]string{
+		"app.yaml":        "port: 80",
+		"db/primary.yaml": "host: h1\nport: 3306\ntls: !include ../../tls.json",
+		"db/replica.json": `{"host":"h2"}`,
+		"db/password":     "secret",
+		"db/README.md":    "unknown format",
+	})
+	s := NewSource(root, WithRecursive(), WithNamespace())
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := keys(kvs), []string{"app.yaml", "db/password", "db/primary.yaml", "db/replica.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("keys want: %v, got: %v", want, got)
+	}
+
+	c := config.New(config.WithSource(s))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for key, want := range map[string]string{
+		"app.port":            "80",
+		"db.primary.host":     "h1",
+		"db.replica.host":     "h2",
+		"db.password":         "secret",
+		"db.primary.tls.cert": "c1",
+	} {
+		if got, err := c.Value(key).String(); err != nil || got != want {
+			t.Errorf("%s want: %s, got: %s (%v)", key, want, got, err)
+		}
+	}
+	e, err := c.Explain("db.primary.port")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := e.Winner(); d.Key != "db/primary.yaml" || d.Line != 2 {
+		t.Errorf("unexpected definition %+v", d)
+	}
+}
+
+func TestWatchRecursive(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"db/primary.yaml": "host: h1"})
+	w, err := NewSource(root, WithRecursive()).Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	writeFiles(t, root, map[string]string{"db/primary.yaml": "host: h2"})
+	got := nextBatch(t, w)
+	if kv := got["db/primary.yaml"]; kv == nil || string(kv.Value) != "host: h2" {
+		t.Errorf("unexpected batch: %v", got)
+	}
+	if err := os.Remove(filepath.Join(root, "db", "primary.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	got = nextBatch(t, w)
+	if kv := got["db/primary.yaml"]; kv == nil || !kv.Deleted {
+		t.Errorf("unexpected batch: %v", got)
+	}
+}
+
+func TestLoadFormats(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.toml":       "[a]\nport = 1",
+		"b.properties": "b.port = 2",
+		"c.ini":        "[c]\nport = 3",
+		"d.hcl":        "d { port = 4 }",
+	})
+	c := config.New(config.WithSource(NewSource(root)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for i, key := range []string{"a.port", "b.port", "c.port", "d.port"} {
+		if v, err := c.Value(key).Int(); err != nil || v != int64(i+1) {
+			t.Errorf("%s = %d %v", key, v, err)
+		}
+	}
+}
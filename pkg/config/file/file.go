@@ -2,22 +2,45 @@ package file
 
 import (
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/banbridge/common/pkg/config"
 )
 
 var _ config.Source = (*file)(nil)
 
+// dataDir is the symlink Kubernetes swaps atomically when a ConfigMap or Secret volume is updated.
+const dataDir = "..data"
+
 type file struct {
-	path string
+	path      string
+	debounce  time.Duration
+	recursive bool
+	namespace bool
+
+	// loaded is the result of the last successful Load, the documents a
+	// new watcher compares its first scan against.
+	mu       sync.Mutex
+	loaded   []*config.KeyValue
+	isLoaded bool
 }
 
-// NewSource new a file source.
-func NewSource(path string) config.Source {
-	return &file{path: path}
+// NewSource new a file source, path can be a file, a directory or a glob
+// pattern such as conf.d/*.yaml. Files of directories and patterns are
+// loaded in lexical order of their path, hidden files and files of a
+// format without a registered codec are skipped.
+func NewSource(path string, opts ...Option) config.Source {
+	f := &file{path: path, debounce: defaultDebounce}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
 func (f *file) loadFile(path string) (*config.KeyValue, error) {
@@ -38,29 +61,115 @@ func (f *file) loadFile(path string) (*config.KeyValue, error) {
 		Key:    info.Name(),
 		Format: format(info.Name()),
 		Value:  data,
+		Path:   path,
 	}, nil
 }
 
 func (f *file) loadDir(path string) (kvs []*config.KeyValue, err error) {
-	files, err := os.ReadDir(path)
+	// the visible files of a ConfigMap volume are symlinks into ..data, read
+	// them all from one resolved ..data so a swap never mixes two versions
+	if real, err := filepath.EvalSymlinks(filepath.Join(path, dataDir)); err == nil {
+		path = real
+	}
+	err = filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == path {
+			return nil
+		}
+		// ignore hidden files
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if !f.recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		kv, err := f.loadEntry(path, name)
+		if err != nil || kv == nil {
+			return err
+		}
+		kvs = append(kvs, kv)
+		return nil
+	})
+	return kvs, err
+}
+
+func (f *file) loadGlob() (kvs []*config.KeyValue, err error) {
+	matches, err := filepath.Glob(f.path)
 	if err != nil {
 		return nil, err
 	}
-	for _, file := range files {
-		// ignore hidden files
-		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+	sort.Strings(matches)
+	root := globRoot(f.path)
+	for _, name := range matches {
+		if strings.HasPrefix(filepath.Base(name), ".") {
 			continue
 		}
-		kv, err := f.loadFile(filepath.Join(path, file.Name()))
+		kv, err := f.loadEntry(root, name)
 		if err != nil {
 			return nil, err
 		}
-		kvs = append(kvs, kv)
+		if kv != nil {
+			kvs = append(kvs, kv)
+		}
+	}
+	return kvs, nil
+}
+
+// loadEntry loads the file name found under root and keys it by its
+// relative path, it returns nil for directories and unknown formats.
+func (f *file) loadEntry(root, name string) (*config.KeyValue, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() || !knownFormat(format(filepath.Base(name))) {
+		return nil, nil
+	}
+	kv, err := f.loadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, name)
+	if err != nil {
+		return nil, err
 	}
-	return
+	kv.Key = filepath.ToSlash(rel)
+	if f.namespace {
+		return namespaced(kv), nil
+	}
+	return kv, nil
 }
 
-func (f *file) Load() (kvs []*config.KeyValue, err error) {
+func (f *file) Load() ([]*config.KeyValue, error) {
+	kvs, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.loaded, f.isLoaded = kvs, true
+	f.mu.Unlock()
+	return kvs, nil
+}
+
+// lastLoad returns the result of the last successful Load.
+func (f *file) lastLoad() ([]*config.KeyValue, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.loaded, f.isLoaded
+}
+
+func (f *file) load() (kvs []*config.KeyValue, err error) {
+	if isGlob(f.path) {
+		return f.loadGlob()
+	}
 	fi, err := os.Stat(f.path)
 	if err != nil {
 		return nil, err
@@ -132,11 +132,11 @@ func testWatchFile(t *testing.T, path string) {
 		t.Error(err)
 	}
 	kvs, err = watch.Next()
-	if err == nil {
+	if err != nil {
 		t.Errorf("watch.Next() error(%v)", err)
 	}
-	if kvs != nil {
-		t.Errorf("watch.Next() error(%v)", err)
+	if len(kvs) != 1 || !kvs[0].Deleted || kvs[0].Key != filepath.Base(path) {
+		t.Errorf("watch.Next() want deleted %s, got %v", filepath.Base(path), kvs)
 	}
 
 	err = watch.Stop()
@@ -159,12 +159,12 @@ func testWatchDir(t *testing.T, path, file string) {
 		t.Error(err)
 	}
 
-	f, err := os.OpenFile(file, os.O_RDWR, 0)
+	f, err := os.OpenFile(file, os.O_RDWR|os.O_TRUNC, 0)
 	if err != nil {
 		t.Error(err)
 	}
 	defer f.Close()
-	_, err = f.WriteString(_testJSONUpdate)
+	_, err = f.WriteString(_testJSON)
 	if err != nil {
 		t.Error(err)
 	}
@@ -173,8 +173,8 @@ func testWatchDir(t *testing.T, path, file string) {
 	if err != nil {
 		t.Errorf("watch.Next() error(%v)", err)
 	}
-	if !reflect.DeepEqual(string(kvs[0].Value), _testJSONUpdate) {
-		t.Errorf("string(kvs[0].Value(%s) is  not equal to _testJSONUpdate(%v)", kvs[0].Value, _testJSONUpdate)
+	if !reflect.DeepEqual(string(kvs[0].Value), _testJSON) {
+		t.Errorf("string(kvs[0].Value(%s) is  not equal to _testJSON(%v)", kvs[0].Value, _testJSON)
 	}
 }
 
@@ -1,10 +1,46 @@
 package file
 
-import "strings"
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/banbridge/common/pkg/config"
+	"github.com/banbridge/common/pkg/encoding"
+)
 
 func format(name string) string {
 	if p := strings.Split(name, "."); len(p) > 1 {
-		return p[len(p)-1]
+		if ext := p[len(p)-1]; ext != "yml" {
+			return ext
+		}
+		return "yaml"
 	}
 	return ""
 }
+
+// knownFormat reports whether a file of format can be decoded, files
+// without an extension are loaded as a plain value of their key.
+func knownFormat(format string) bool {
+	return format == "" || encoding.GetCodec(format) != nil
+}
+
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// globRoot returns the directory before the first path element with a pattern.
+func globRoot(pattern string) string {
+	dir := filepath.Dir(pattern)
+	for isGlob(dir) {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// namespaced nests the content of kv under the key derived from its
+// relative path, db/primary.yaml is loaded under db.primary.
+func namespaced(kv *config.KeyValue) *config.KeyValue {
+	ns := strings.TrimSuffix(kv.Key, filepath.Ext(kv.Key))
+	kv.Namespace = strings.Split(ns, "/")
+	return kv
+}
@@ -0,0 +1,31 @@
+package file
+
+import "time"
+
+const defaultDebounce = 100 * time.Millisecond
+
+// Option is file source option.
+type Option func(*file)
+
+// WithDebounce waits until no file event arrived for d before reloading, so
+// bursts of events caused by a single save are reloaded once.
+func WithDebounce(d time.Duration) Option {
+	return func(f *file) {
+		f.debounce = d
+	}
+}
+
+// WithRecursive loads the files of subdirectories too.
+func WithRecursive() Option {
+	return func(f *file) {
+		f.recursive = true
+	}
+}
+
+// WithNamespace nests the content of each file of a directory or pattern
+// under a key derived from its relative path, db/primary.yaml under db.primary.
+func WithNamespace() Option {
+	return func(f *file) {
+		f.namespace = true
+	}
+}
@@ -1,9 +1,14 @@
 package file
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 
@@ -12,57 +17,188 @@ import (
 
 var _ config.Watcher = (*watcher)(nil)
 
+// watcher watches the directory of the source, so renames, editors saving
+// through a temporary file and ConfigMap symlink swaps are all seen as
+// events of the directory rather than of a file that may be replaced.
 type watcher struct {
-	f  *file
-	fw *fsnotify.Watcher
+	f    *file
+	fw   *fsnotify.Watcher
+	last map[string]*config.KeyValue
+	// rescan makes the first Next scan the source without waiting for an
+	// event, to catch the changes made before the directories were watched.
+	rescan bool
 
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
+// newWatcher watches the directories of f before it takes the documents of
+// the last Load, the ones the config merged, as the current ones, so a
+// change made in between is found by the first rescan.
 func newWatcher(f *file) (config.Watcher, error) {
 	fw, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, err
 	}
-	if err := fw.Add(f.path); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watcher{f: f, fw: fw, rescan: true, ctx: ctx, cancel: cancel}
+	if err := w.addDirs(); err != nil {
+		_ = w.Stop()
 		return nil, err
 	}
-	ctx, cancel := context.WithCancel(context.Background())
-	return &watcher{f: f, fw: fw, ctx: ctx, cancel: cancel}, nil
+	kvs, ok := f.lastLoad()
+	if !ok {
+		if kvs, err = f.load(); err != nil {
+			_ = w.Stop()
+			return nil, err
+		}
+	}
+	w.last = index(kvs)
+	return w, nil
 }
 
-func (w *watcher) Next() ([]*config.KeyValue, error) {
-	select {
-	case <-w.ctx.Done():
-		return nil, w.ctx.Err()
-	case event := <-w.fw.Events:
-		if event.Op == fsnotify.Rename {
-			if _, err := os.Stat(event.Name); err == nil || os.IsExist(err) {
-				if err := w.fw.Add(event.Name); err != nil {
-					return nil, err
-				}
-			}
+// addDirs watches the directories the source loads from, it is called
+// again after every reload to pick up new subdirectories.
+func (w *watcher) addDirs() error {
+	var dirs []string
+	switch {
+	case isGlob(w.f.path):
+		dirs = append(dirs, globRoot(w.f.path))
+		matches, err := filepath.Glob(w.f.path)
+		if err != nil {
+			return err
 		}
+		for _, m := range matches {
+			dirs = append(dirs, filepath.Dir(m))
+		}
+	default:
 		fi, err := os.Stat(w.f.path)
 		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			dirs = append(dirs, filepath.Dir(w.f.path))
+			break
+		}
+		dirs = append(dirs, w.f.path)
+		if !w.f.recursive {
+			break
+		}
+		err = filepath.WalkDir(w.f.path, func(name string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() || name == w.f.path {
+				return err
+			}
+			if strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, name)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, dir := range dirs {
+		if err := w.fw.Add(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Next blocks until the content of the source changed, it returns all
+// files of the source as one batch plus the removed ones with Deleted set.
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	for {
+		if w.rescan {
+			w.rescan = false
+		} else if err := w.wait(); err != nil {
 			return nil, err
 		}
-		path := w.f.path
-		if fi.IsDir() {
-			path = filepath.Join(w.f.path, filepath.Base(event.Name))
+		kvs, err := w.f.load()
+		if errors.Is(err, os.ErrNotExist) {
+			// the file was removed or is being replaced
+			kvs, err = nil, nil
 		}
-		kv, err := w.f.loadFile(path)
 		if err != nil {
 			return nil, err
 		}
-		return []*config.KeyValue{kv}, nil
+		if err := w.addDirs(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if batch := w.batch(kvs); batch != nil {
+			return batch, nil
+		}
+	}
+}
+
+// wait blocks until an event arrived and settled.
+func (w *watcher) wait() error {
+	select {
+	case <-w.ctx.Done():
+		return w.ctx.Err()
 	case err := <-w.fw.Errors:
-		return nil, err
+		return err
+	case <-w.fw.Events:
+	}
+	return w.settle()
+}
+
+// settle drains events until none arrived for the debounce interval.
+func (w *watcher) settle() error {
+	if w.f.debounce <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(w.f.debounce)
+	defer timer.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return w.ctx.Err()
+		case err := <-w.fw.Errors:
+			return err
+		case <-w.fw.Events:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(w.f.debounce)
+		case <-timer.C:
+			return nil
+		}
+	}
+}
+
+// batch returns kvs and the deleted keys, or nil when nothing changed since the last batch.
+func (w *watcher) batch(kvs []*config.KeyValue) []*config.KeyValue {
+	next := index(kvs)
+	changed := len(next) != len(w.last)
+	for k, kv := range next {
+		if old, ok := w.last[k]; !ok || !bytes.Equal(old.Value, kv.Value) {
+			changed = true
+		}
 	}
+	if !changed {
+		return nil
+	}
+	batch := kvs
+	for k := range w.last {
+		if _, ok := next[k]; !ok {
+			batch = append(batch, &config.KeyValue{Key: k, Deleted: true})
+		}
+	}
+	w.last = next
+	return batch
 }
 
 func (w *watcher) Stop() error {
 	w.cancel()
 	return w.fw.Close()
 }
+
+func index(kvs []*config.KeyValue) map[string]*config.KeyValue {
+	m := make(map[string]*config.KeyValue, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv
+	}
+	return m
+}
@@ -0,0 +1,123 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/banbridge/common/pkg/config"
+)
+
+func nextBatch(t *testing.T, w config.Watcher) map[string]*config.KeyValue {
+	t.Helper()
+	kvs, err := w.Next()
+	if err != nil {
+		t.Fatalf("watch.Next() error(%v)", err)
+	}
+	return index(kvs)
+}
+
+func TestWatchConfigMapSwap(t *testing.T) {
+	dir := t.TempDir()
+	v1 := filepath.Join(dir, "..v1")
+	v2 := filepath.Join(dir, "..v2")
+	for path, data := range map[string]string{
+		filepath.Join(v1, "app.yaml"):   "port: 80",
+		filepath.Join(v1, "other.yaml"): "name: other",
+		filepath.Join(v2, "app.yaml"):   "port: 8080",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("..v1", filepath.Join(dir, dataDir)); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"app.yaml", "other.yaml"} {
+		if err := os.Symlink(filepath.Join(dataDir, name), filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := NewSource(dir)
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := index(kvs); len(got) != 2 || string(got["app.yaml"].Value) != "port: 80" {
+		t.Fatalf("unexpected load: %v", kvs)
+	}
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// the atomic writer swaps ..data through a rename of a temporary symlink
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink("..v2", tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, dataDir)); err != nil {
+		t.Fatal(err)
+	}
+	got := nextBatch(t, w)
+	if kv := got["app.yaml"]; kv == nil || string(kv.Value) != "port: 8080" {
+		t.Errorf("app.yaml want: port: 8080, got: %v", kv)
+	}
+	if kv := got["other.yaml"]; kv == nil || !kv.Deleted {
+		t.Errorf("other.yaml want deleted, got: %v", kv)
+	}
+}
+
+func TestWatchRenameOver(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.json")
+	if err := os.WriteFile(path, []byte(`{"port":80}`), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewSource(path).Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// editors write a temporary file and rename it over the original
+	tmp := filepath.Join(dir, ".app.json.swp")
+	if err := os.WriteFile(tmp, []byte(`{"port":8080}`), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	got := nextBatch(t, w)
+	if kv := got["app.json"]; len(got) != 1 || kv == nil || string(kv.Value) != `{"port":8080}` {
+		t.Errorf("unexpected batch: %v", got)
+	}
+}
+
+func TestWatchChangeBeforeWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("port: 80"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	s := NewSource(path)
+	if _, err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	// written after the documents were loaded but before they are watched
+	if err := os.WriteFile(path, []byte("port: 8080"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if kv := nextBatch(t, w)["app.yaml"]; kv == nil || string(kv.Value) != "port: 8080" {
+		t.Errorf("app.yaml want: port: 8080, got: %v", kv)
+	}
+}
@@ -0,0 +1,94 @@
+package flag
+
+import (
+	goflag "flag"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/banbridge/common/pkg/config"
+)
+
+var _ config.Source = (*flagSource)(nil)
+
+type flagSource struct {
+	fs  *pflag.FlagSet
+	std *goflag.FlagSet
+}
+
+// NewSource new a flag source from a pflag set, only flags explicitly set
+// on the command line are loaded, "--server.port=9000" is loaded as server.port.
+func NewSource(fs *pflag.FlagSet) config.Source {
+	return &flagSource{fs: fs}
+}
+
+// NewStdSource new a flag source from a standard library flag set.
+func NewStdSource(fs *goflag.FlagSet) config.Source {
+	return &flagSource{std: fs}
+}
+
+// NewCommandSource new a flag source from the local and persistent flags of a cobra command.
+func NewCommandSource(cmd *cobra.Command) config.Source {
+	return &flagSource{fs: cmd.Flags()}
+}
+
+func (s *flagSource) Load() (kvs []*config.KeyValue, err error) {
+	add := func(name string, value interface{}) {
+		if err != nil {
+			return
+		}
+		var data []byte
+		if data, err = sonic.Marshal(config.Nest(name, value)); err == nil {
+			kvs = append(kvs, &config.KeyValue{
+				Key:    name,
+				Value:  data,
+				Format: "json",
+			})
+		}
+	}
+	if s.std != nil {
+		s.std.Visit(func(f *goflag.Flag) {
+			if g, ok := f.Value.(goflag.Getter); ok {
+				add(f.Name, g.Get())
+			} else {
+				add(f.Name, f.Value.String())
+			}
+		})
+	}
+	if s.fs != nil {
+		s.fs.Visit(func(f *pflag.Flag) {
+			add(f.Name, pflagValue(f.Value))
+		})
+	}
+	return kvs, err
+}
+
+func (s *flagSource) Watch() (config.Watcher, error) {
+	return newWatcher(), nil
+}
+
+// pflagValue converts a pflag value to a typed value by its type name.
+func pflagValue(v pflag.Value) interface{} {
+	if sv, ok := v.(pflag.SliceValue); ok {
+		return sv.GetSlice()
+	}
+	s := v.String()
+	switch t := v.Type(); {
+	case t == "bool":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case strings.HasPrefix(t, "int"), strings.HasPrefix(t, "uint"):
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+	case strings.HasPrefix(t, "float"):
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
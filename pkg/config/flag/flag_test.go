@@ -0,0 +1,143 @@
+package flag
+
+import (
+	goflag "flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/banbridge/common/pkg/config"
+	"github.com/banbridge/common/pkg/config/file"
+)
+
+type testServer struct {
+	Addr    string        `config:"addr" default:"0.0.0.0" usage:"listen address"`
+	Port    int           `config:"port" default:"8080"`
+	Timeout time.Duration `config:"timeout" default:"1s"`
+	Hosts   []string      `config:"hosts"`
+	Debug   bool          `config:"debug"`
+}
+
+type testConf struct {
+	Server testServer `config:"server"`
+}
+
+func TestRegister(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := Register(fs, "", &testConf{}); err != nil {
+		t.Fatal(err)
+	}
+	for name, def := range map[string]string{
+		"server.addr":    "0.0.0.0",
+		"server.port":    "8080",
+		"server.timeout": "1s",
+		"server.hosts":   "[]",
+		"server.debug":   "false",
+	} {
+		f := fs.Lookup(name)
+		if f == nil {
+			t.Errorf("flag %s not registered", name)
+			continue
+		}
+		if f.DefValue != def {
+			t.Errorf("flag %s default want: %s, got: %s", name, def, f.DefValue)
+		}
+	}
+	if err := Register(fs, "", testConf{}); err == nil {
+		t.Error("register a non pointer should fail")
+	}
+	if err := Register(fs, "", &testConf{}); err == nil {
+		t.Error("register an already defined flag should fail")
+	}
+	for _, v := range []interface{}{
+		&struct {
+			Ports []int `config:"ports"`
+		}{},
+		&struct {
+			Labels map[string]string `config:"labels"`
+		}{},
+	} {
+		if err := Register(pflag.NewFlagSet("test", pflag.ContinueOnError), "", v); err == nil {
+			t.Errorf("register %T should fail", v)
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.json")
+	if err := os.WriteFile(path, []byte(`{"server":{"addr":"127.0.0.1","port":80,"debug":true}}`), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := Register(fs, "", &testConf{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Parse([]string{"--server.port=9000", "--server.hosts=a,b", "--server.timeout=1m"}); err != nil {
+		t.Fatal(err)
+	}
+	c := config.New(
+		config.WithLayer(config.LayerFile, "file", file.NewSource(path)),
+		config.WithLayer(config.LayerFlags, "flags", NewSource(fs)),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	conf, err := config.Bind[testConf](c, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testServer{
+		Addr:    "127.0.0.1",
+		Port:    9000,
+		Timeout: time.Minute,
+		Hosts:   []string{"a", "b"},
+		Debug:   true,
+	}
+	if !reflect.DeepEqual(conf.Server, want) {
+		t.Errorf("server want: %+v, got: %+v", want, conf.Server)
+	}
+}
+
+func TestStdSource(t *testing.T) {
+	fs := goflag.NewFlagSet("test", goflag.ContinueOnError)
+	fs.Int("server.port", 80, "")
+	fs.String("server.addr", "0.0.0.0", "")
+	if err := fs.Parse([]string{"-server.port=9000"}); err != nil {
+		t.Fatal(err)
+	}
+	kvs, err := NewStdSource(fs).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || kvs[0].Key != "server.port" || string(kvs[0].Value) != `{"server":{"port":9000}}` {
+		t.Errorf("unexpected kvs: %v", kvs)
+	}
+}
+
+func TestCommandSource(t *testing.T) {
+	var kvs []*config.KeyValue
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().String("log.level", "info", "")
+	cmd := &cobra.Command{
+		Use: "serve",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			kvs, err = NewCommandSource(cmd).Load()
+			return err
+		},
+	}
+	cmd.Flags().Int("server.port", 80, "")
+	root.AddCommand(cmd)
+	root.SetArgs([]string{"serve", "--log.level=debug"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || string(kvs[0].Value) != `{"log":{"level":"debug"}}` {
+		t.Errorf("unexpected kvs: %v", kvs)
+	}
+}
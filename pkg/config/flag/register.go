@@ -0,0 +1,154 @@
+package flag
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/banbridge/common/pkg/config"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Register defines a flag for every field of the struct pointed to by v,
+// named by the same tags config.Bind reads and prefixed with prefix, e.g.
+//
+//	Port int `config:"port" default:"8080" usage:"listen port"`
+//
+// registered with prefix "server" defines --server.port. The default comes
+// from the `default` tag or the current field value. It fails for a flag
+// that is already defined and for a field type without a flag type.
+func Register(fs *pflag.FlagSet, prefix string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("flag: register target must be a non-nil struct pointer, got %T", v)
+	}
+	return register(fs, prefix, rv.Elem())
+}
+
+func register(fs *pflag.FlagSet, prefix string, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := config.FieldName(field)
+		if name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct {
+			if fv.IsNil() {
+				fv = reflect.New(fv.Type().Elem())
+			}
+			fv = fv.Elem()
+		}
+		if field.Anonymous && name == "" && fv.Kind() == reflect.Struct {
+			if err := register(fs, prefix, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if fv.Kind() == reflect.Struct {
+			if err := register(fs, name, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if fs.Lookup(name) != nil {
+			return fmt.Errorf("flag: %s is already defined", name)
+		}
+		def, hasDef := field.Tag.Lookup("default")
+		if err := define(fs, name, field.Tag.Get("usage"), fv, def, hasDef); err != nil {
+			return fmt.Errorf("flag: %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func define(fs *pflag.FlagSet, name, usage string, fv reflect.Value, def string, hasDef bool) error {
+	switch {
+	case fv.Type() == durationType:
+		d := time.Duration(fv.Int())
+		if hasDef {
+			var err error
+			if d, err = time.ParseDuration(def); err != nil {
+				return err
+			}
+		}
+		fs.Duration(name, d, usage)
+		return nil
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.String:
+		var s []string
+		if hasDef {
+			if def != "" {
+				s = strings.Split(def, ",")
+			}
+		} else {
+			s = make([]string, fv.Len())
+			for i := range s {
+				s[i] = fv.Index(i).String()
+			}
+		}
+		fs.StringSlice(name, s, usage)
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.Bool:
+		b := fv.Bool()
+		if hasDef {
+			var err error
+			if b, err = strconv.ParseBool(def); err != nil {
+				return err
+			}
+		}
+		fs.Bool(name, b, usage)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := fv.Int()
+		if hasDef {
+			var err error
+			if n, err = strconv.ParseInt(def, 10, 64); err != nil {
+				return err
+			}
+		}
+		fs.Int64(name, n, usage)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := fv.Uint()
+		if hasDef {
+			var err error
+			if n, err = strconv.ParseUint(def, 10, 64); err != nil {
+				return err
+			}
+		}
+		fs.Uint64(name, n, usage)
+	case reflect.Float32, reflect.Float64:
+		f := fv.Float()
+		if hasDef {
+			var err error
+			if f, err = strconv.ParseFloat(def, 64); err != nil {
+				return err
+			}
+		}
+		fs.Float64(name, f, usage)
+	case reflect.String:
+		s := fv.String()
+		if hasDef {
+			s = def
+		}
+		fs.String(name, s, usage)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
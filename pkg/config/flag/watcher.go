@@ -0,0 +1,30 @@
+package flag
+
+import (
+	"context"
+
+	"github.com/banbridge/common/pkg/config"
+)
+
+var _ config.Watcher = (*watcher)(nil)
+
+type watcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newWatcher() config.Watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &watcher{ctx: ctx, cancel: cancel}
+}
+
+// Next will be blocked until the Stop method is called, flags never change after parsing.
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	<-w.ctx.Done()
+	return nil, w.ctx.Err()
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	return nil
+}
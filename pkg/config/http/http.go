@@ -0,0 +1,240 @@
+// Package http provides a config source fetching a document over HTTP.
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/banbridge/common/pkg/config"
+	"github.com/banbridge/common/pkg/encoding"
+	"github.com/banbridge/common/pkg/logs"
+)
+
+var _ config.Source = (*source)(nil)
+
+// errNotModified is returned by fetch when the document did not change.
+var errNotModified = errors.New("http: not modified")
+
+type source struct {
+	url    string
+	client *http.Client
+	header http.Header
+	format string
+	key    string
+	cache  string
+
+	interval               time.Duration
+	longPoll               time.Duration
+	minBackoff, maxBackoff time.Duration
+
+	lock sync.Mutex
+	last *document
+}
+
+// document is a fetched payload, it is also the content of the cache file.
+type document struct {
+	ETag   string `json:"etag,omitempty"`
+	Format string `json:"format"`
+	Value  []byte `json:"value"`
+}
+
+// NewSource new a source fetching the document at url. The format of the
+// document is inferred from the Content-Type of the response, or from the
+// extension of the url, and must have a codec registered in pkg/encoding.
+func NewSource(url string, opts ...Option) config.Source {
+	s := &source{
+		url:        url,
+		client:     &http.Client{Timeout: 30 * time.Second},
+		header:     make(http.Header),
+		interval:   defaultInterval,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.key == "" {
+		s.key = url
+	}
+	return s
+}
+
+// Load fetches the document, it falls back to the cache file when the
+// endpoint cannot be reached or the document does not decode.
+func (s *source) Load() ([]*config.KeyValue, error) {
+	doc, err := s.fetch(context.Background(), "")
+	if err == nil {
+		err = s.decodes(doc)
+	}
+	if err == nil {
+		s.cacheDocument(doc)
+	} else {
+		cached, cerr := s.readCache()
+		if cerr != nil {
+			return nil, err
+		}
+		logs.Warn("failed to fetch config %s, load the cached one: %v", s.url, err)
+		doc = cached
+	}
+	s.lock.Lock()
+	s.last = doc
+	s.lock.Unlock()
+	return []*config.KeyValue{s.keyValue(doc)}, nil
+}
+
+// loaded returns the document of the last Load.
+func (s *source) loaded() *document {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.last
+}
+
+func (s *source) Watch() (config.Watcher, error) {
+	return newWatcher(s)
+}
+
+func (s *source) keyValue(doc *document) *config.KeyValue {
+	return &config.KeyValue{Key: s.key, Value: doc.Value, Format: doc.Format}
+}
+
+// fetch requests the document, errNotModified is returned when etag still matches.
+func (s *source) fetch(ctx context.Context, etag string) (*document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range s.header {
+		req.Header[k] = v
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+		if s.longPoll > 0 {
+			req.Header.Set("Prefer", "wait="+strconv.Itoa(int(s.longPoll.Seconds())))
+		}
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, errNotModified
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http: fetch %s: %s", s.url, resp.Status)
+	}
+	format, err := s.inferFormat(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	return &document{ETag: resp.Header.Get("ETag"), Format: format, Value: data}, nil
+}
+
+// decodes reports whether doc decodes with the codec of its format.
+func (s *source) decodes(doc *document) error {
+	codec := encoding.GetCodec(doc.Format)
+	if codec == nil {
+		return fmt.Errorf("http: no codec for format %q of %s", doc.Format, s.url)
+	}
+	var v map[string]interface{}
+	if err := codec.Unmarshal(doc.Value, &v); err != nil {
+		return fmt.Errorf("http: decode %s: %w", s.url, err)
+	}
+	return nil
+}
+
+// cacheDocument keeps doc as the last good document.
+func (s *source) cacheDocument(doc *document) {
+	if err := s.writeCache(doc); err != nil {
+		logs.Warn("failed to cache config %s: %v", s.url, err)
+	}
+}
+
+// inferFormat maps the Content-Type to a codec: application/json,
+// application/x-yaml, text/yaml and application/vnd.petal+json all work.
+func (s *source) inferFormat(contentType string) (string, error) {
+	if s.format != "" {
+		return s.format, nil
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		_, sub, _ := strings.Cut(mediaType, "/")
+		if _, suffix, ok := strings.Cut(sub, "+"); ok {
+			sub = suffix
+		}
+		sub = strings.TrimPrefix(sub, "x-")
+		if sub == "yml" {
+			sub = "yaml"
+		}
+		if encoding.GetCodec(sub) != nil {
+			return sub, nil
+		}
+	}
+	if u, err := url.Parse(s.url); err == nil {
+		ext := strings.TrimPrefix(path.Ext(u.Path), ".")
+		if ext == "yml" {
+			ext = "yaml"
+		}
+		if ext != "" && encoding.GetCodec(ext) != nil {
+			return ext, nil
+		}
+	}
+	return "", fmt.Errorf("http: no codec for content type %q of %s", contentType, s.url)
+}
+
+func (s *source) readCache() (*document, error) {
+	if s.cache == "" {
+		return nil, os.ErrNotExist
+	}
+	data, err := os.ReadFile(s.cache)
+	if err != nil {
+		return nil, err
+	}
+	doc := &document{}
+	if err := sonic.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// writeCache replaces the cache file through a rename, so a crash never
+// leaves a truncated cache behind.
+func (s *source) writeCache(doc *document) error {
+	if s.cache == "" {
+		return nil
+	}
+	data, err := sonic.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.cache), filepath.Base(s.cache)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.cache)
+}
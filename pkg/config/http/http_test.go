@@ -0,0 +1,287 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/banbridge/common/pkg/config"
+)
+
+// server serves a document with a versioned ETag.
+type server struct {
+	lock        sync.Mutex
+	body        string
+	contentType string
+	version     int
+	requests    []*http.Request
+}
+
+func (s *server) set(body string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.body = body
+	s.version++
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.requests = append(s.requests, r)
+	etag := fmt.Sprintf(`"v%d"`, s.version)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", s.contentType)
+	_, _ = w.Write([]byte(s.body))
+}
+
+func (s *server) lastRequest() *http.Request {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.requests[len(s.requests)-1]
+}
+
+func TestLoad(t *testing.T) {
+	srv := &server{body: "a: 1", contentType: "application/x-yaml; charset=utf-8"}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	s := NewSource(ts.URL+"/config", WithBearerToken("token"))
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || kvs[0].Format != "yaml" || string(kvs[0].Value) != "a: 1" || kvs[0].Key != ts.URL+"/config" {
+		t.Fatalf("unexpected kvs %+v", kvs[0])
+	}
+	if got := srv.lastRequest().Header.Get("Authorization"); got != "Bearer token" {
+		t.Fatalf("Authorization = %q", got)
+	}
+}
+
+func TestLoadRejectsInclude(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret.json")
+	if err := os.WriteFile(secret, []byte(`{"key":"local"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{
+		`{"a":{"$include":"` + filepath.ToSlash(secret) + `"}}`,
+		"a: !include " + filepath.ToSlash(secret),
+	} {
+		srv := &server{body: body, contentType: "application/json"}
+		if !strings.HasPrefix(body, "{") {
+			srv.contentType = "application/yaml"
+		}
+		ts := httptest.NewServer(srv)
+		c := config.New(config.WithSource(NewSource(ts.URL)))
+		err := c.Load()
+		ts.Close()
+		if err == nil || !strings.Contains(err.Error(), "$include") {
+			t.Errorf("%s: expected the include to be rejected, got: %v", body, err)
+		}
+	}
+}
+
+func TestInferFormat(t *testing.T) {
+	tests := []struct {
+		url         string
+		contentType string
+		want        string
+	}{
+		{"http://x/c", "application/json", "json"},
+		{"http://x/c", "text/yaml", "yaml"},
+		{"http://x/c", "application/vnd.petal+json", "json"},
+		{"http://x/c", "application/yml", "yaml"},
+		{"http://x/c.yml", "text/plain", "yaml"},
+		{"http://x/c.json?v=1", "", "json"},
+		{"http://x/c", "text/plain", ""},
+	}
+	for _, test := range tests {
+		s := NewSource(test.url).(*source)
+		got, err := s.inferFormat(test.contentType)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("%s %s: expected an error, got %s", test.url, test.contentType, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("%s %s: got %q %v, want %q", test.url, test.contentType, got, err, test.want)
+		}
+	}
+}
+
+func TestLoadCache(t *testing.T) {
+	srv := &server{body: `{"a":1}`, contentType: "application/json"}
+	ts := httptest.NewServer(srv)
+	cache := filepath.Join(t.TempDir(), "config.cache")
+
+	if _, err := NewSource(ts.URL, WithCacheFile(cache)).Load(); err != nil {
+		t.Fatal(err)
+	}
+	ts.Close()
+
+	kvs, err := NewSource(ts.URL, WithCacheFile(cache)).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kvs[0].Format != "json" || string(kvs[0].Value) != `{"a":1}` {
+		t.Fatalf("unexpected cached kv %+v", kvs[0])
+	}
+	if _, err := NewSource(ts.URL).Load(); err == nil {
+		t.Fatal("expected an error without cache")
+	}
+}
+
+func TestCacheKeepsLastGood(t *testing.T) {
+	srv := &server{body: `{"a":1}`, contentType: "application/json"}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	cache := filepath.Join(t.TempDir(), "config.cache")
+	cached := func() string {
+		t.Helper()
+		doc, err := (&source{cache: cache}).readCache()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(doc.Value)
+	}
+
+	c := config.New(config.WithSource(NewSource(ts.URL, WithCacheFile(cache), WithPollInterval(10*time.Millisecond))))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// a malformed update is rejected by the reload and not cached
+	srv.set(`{"a":`)
+	deadline := time.Now().Add(time.Second)
+	for c.LastError() == nil && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if c.LastError() == nil {
+		t.Fatal("malformed update not rejected")
+	}
+	if got := cached(); got != `{"a":1}` {
+		t.Fatalf("cache replaced by a rejected update: %s", got)
+	}
+	// so is a malformed document fetched by Load
+	if kvs, err := NewSource(ts.URL, WithCacheFile(cache)).Load(); err != nil || string(kvs[0].Value) != `{"a":1}` {
+		t.Fatalf("Load did not fall back to the cache: %v", err)
+	}
+
+	srv.set(`{"a":2}`)
+	deadline = time.Now().Add(time.Second)
+	for cached() != `{"a":2}` && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := cached(); got != `{"a":2}` {
+		t.Fatalf("committed update not cached: %s", got)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	srv := &server{body: `{"a":1}`, contentType: "application/json"}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	s := NewSource(ts.URL, WithPollInterval(10*time.Millisecond))
+	if _, err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	time.AfterFunc(50*time.Millisecond, func() { srv.set(`{"a":2}`) })
+	kvs, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(kvs[0].Value) != `{"a":2}` {
+		t.Fatalf("unexpected kv %s", kvs[0].Value)
+	}
+	if got := srv.lastRequest().Header.Get("If-None-Match"); got != `"v0"` {
+		t.Fatalf("If-None-Match = %q", got)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := w.Next()
+		done <- err
+	}()
+	_ = w.Stop()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected an error after Stop")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Next did not return after Stop")
+	}
+}
+
+func TestWatchLongPoll(t *testing.T) {
+	srv := &server{body: `{"a":1}`, contentType: "application/json"}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	s := NewSource(ts.URL, WithLongPoll(5*time.Second))
+	if _, err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	srv.set(`{"a":2}`)
+	kvs, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(kvs[0].Value) != `{"a":2}` {
+		t.Fatalf("unexpected kv %s", kvs[0].Value)
+	}
+	if got := srv.lastRequest().Header.Get("Prefer"); got != "wait=5" {
+		t.Fatalf("Prefer = %q", got)
+	}
+}
+
+func TestWatchLongPollIgnored(t *testing.T) {
+	// the server answers at once instead of holding the request
+	srv := &server{body: `{"a":1}`, contentType: "application/json"}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	s := NewSource(ts.URL, WithLongPoll(5*time.Second), WithPollInterval(50*time.Millisecond))
+	if _, err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _, _ = w.Next() }()
+	time.Sleep(200 * time.Millisecond)
+	_ = w.Stop()
+
+	srv.lock.Lock()
+	requests := len(srv.requests)
+	srv.lock.Unlock()
+	if requests > 6 {
+		t.Errorf("%d requests in 200ms with a 50ms interval", requests)
+	}
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"encoding/base64"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultInterval   = 30 * time.Second
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = time.Minute
+)
+
+// Option is http source option.
+type Option func(*source)
+
+// WithClient sends the requests with client instead of a client with a 30s timeout.
+func WithClient(client *http.Client) Option {
+	return func(s *source) {
+		s.client = client
+	}
+}
+
+// WithHeader adds a header to every request.
+func WithHeader(key, value string) Option {
+	return func(s *source) {
+		s.header.Add(key, value)
+	}
+}
+
+// WithBearerToken authenticates the requests with an Authorization bearer token.
+func WithBearerToken(token string) Option {
+	return func(s *source) {
+		s.header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithBasicAuth authenticates the requests with HTTP basic auth.
+func WithBasicAuth(username, password string) Option {
+	return func(s *source) {
+		s.header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+	}
+}
+
+// WithPollInterval polls the endpoint every interval, the default is 30s.
+func WithPollInterval(interval time.Duration) Option {
+	return func(s *source) {
+		s.interval = interval
+	}
+}
+
+// WithLongPoll asks the endpoint to hold every request for up to wait
+// until the document changes, with the Prefer: wait header of RFC 7240,
+// and polls again as soon as a request returns. A request returning before
+// wait without a new document is followed by the poll interval, so a server
+// ignoring the header is not polled in a loop. The client timeout must be
+// longer than wait.
+func WithLongPoll(wait time.Duration) Option {
+	return func(s *source) {
+		s.longPoll = wait
+	}
+}
+
+// WithBackoff retries a failed request after min, doubling up to max.
+func WithBackoff(min, max time.Duration) Option {
+	return func(s *source) {
+		s.minBackoff, s.maxBackoff = min, max
+	}
+}
+
+// WithCacheFile keeps the last good document in path, Load falls back to
+// it when the endpoint cannot be reached.
+func WithCacheFile(path string) Option {
+	return func(s *source) {
+		s.cache = path
+	}
+}
+
+// WithFormat sets the format of the document instead of inferring it from the Content-Type.
+func WithFormat(format string) Option {
+	return func(s *source) {
+		s.format = format
+	}
+}
+
+// WithKey sets the key of the loaded KeyValue, the url by default.
+func WithKey(key string) Option {
+	return func(s *source) {
+		s.key = key
+	}
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/banbridge/common/pkg/config"
+	"github.com/banbridge/common/pkg/logs"
+)
+
+var (
+	_ config.Watcher   = (*watcher)(nil)
+	_ config.Committer = (*watcher)(nil)
+)
+
+// watcher polls the endpoint with the ETag of the last document, long
+// polling requests are sent back to back, periodic ones every interval.
+type watcher struct {
+	s       *source
+	last    *document
+	backoff time.Duration
+	// early is set when a long polling request returned before the wait
+	// without a new document, the server does not hold the requests.
+	early bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newWatcher(s *source) (config.Watcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	// start from the document Load returned, fetched or cached
+	return &watcher{s: s, last: s.loaded(), ctx: ctx, cancel: cancel}, nil
+}
+
+// Next blocks until the document changed.
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	for {
+		if err := w.sleep(w.wait()); err != nil {
+			return nil, err
+		}
+		var etag string
+		if w.last != nil {
+			etag = w.last.ETag
+		}
+		started := time.Now()
+		doc, err := w.s.fetch(w.ctx, etag)
+		w.early = w.s.longPoll > 0 && time.Since(started) < w.s.longPoll
+		switch {
+		case w.ctx.Err() != nil:
+			return nil, w.ctx.Err()
+		case errors.Is(err, errNotModified):
+			w.backoff = 0
+			continue
+		case err != nil:
+			w.backoff = w.nextBackoff()
+			logs.Warn("failed to poll config %s, retry in %s: %v", w.s.url, w.backoff, err)
+			continue
+		}
+		w.backoff = 0
+		if w.last != nil && w.last.Format == doc.Format && bytes.Equal(w.last.Value, doc.Value) {
+			// servers without ETag return the same document
+			w.last = doc
+			continue
+		}
+		w.last = doc
+		w.early = false
+		return []*config.KeyValue{w.s.keyValue(doc)}, nil
+	}
+}
+
+// Commit caches the document of the last Next once the config merged it.
+func (w *watcher) Commit([]*config.KeyValue) {
+	w.s.cacheDocument(w.last)
+}
+
+// wait returns the delay before the next request.
+func (w *watcher) wait() time.Duration {
+	if w.backoff > 0 {
+		return w.backoff
+	}
+	if w.s.longPoll > 0 && !w.early {
+		return 0
+	}
+	return w.s.interval
+}
+
+func (w *watcher) nextBackoff() time.Duration {
+	if w.backoff == 0 {
+		return w.s.minBackoff
+	}
+	if next := w.backoff * 2; next < w.s.maxBackoff {
+		return next
+	}
+	return w.s.maxBackoff
+}
+
+func (w *watcher) sleep(d time.Duration) error {
+	if d <= 0 {
+		return w.ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	return nil
+}
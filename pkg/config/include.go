@@ -0,0 +1,230 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// includeKey pulls the files it names into the map holding it, the other
+	// keys of the map override the included content.
+	includeKey = "$include"
+	// includeTag is the yaml form of includeKey, key: !include base.yaml.
+	includeTag = "!include"
+	// maxIncludeDepth limits how deep included files may include further files.
+	maxIncludeDepth = 8
+)
+
+// ErrIncludeCycle is returned when a file includes itself through other files.
+var ErrIncludeCycle = errors.New("config: include cycle")
+
+// includer resolves the include directives of one KeyValue, files are
+// resolved relative to the file including them.
+type includer struct {
+	r     *reader
+	stack []string
+}
+
+// decode decodes kv, resolves its include directives and nests the values
+// under its namespace.
+func (r *reader) decode(kv *KeyValue) (map[string]interface{}, error) {
+	if len(kv.Namespace) > 0 && kv.Format == "" {
+		return nest(kv.Namespace, string(kv.Value)), nil
+	}
+	in := &includer{r: r}
+	if kv.Path != "" {
+		path, err := filepath.Abs(kv.Path)
+		if err != nil {
+			return nil, err
+		}
+		in.stack = append(in.stack, path)
+	}
+	values, err := in.decode(kv)
+	if err != nil || len(kv.Namespace) == 0 {
+		return values, err
+	}
+	return nest(kv.Namespace, values), nil
+}
+
+func (in *includer) decode(kv *KeyValue) (map[string]interface{}, error) {
+	if kv.Format == "yaml" || kv.Format == "yml" {
+		data, err := rewriteIncludeTags(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		kv = &KeyValue{Key: kv.Key, Value: data, Format: kv.Format, Path: kv.Path}
+	}
+	next := make(map[string]interface{})
+	if err := in.r.opts.decoder(kv, next); err != nil {
+		return nil, err
+	}
+	values := convertMap(next).(map[string]interface{})
+	if kv.Path == "" {
+		// documents of http, kv and other remote sources must not read local files
+		if err := rejectIncludes(kv.Key, values); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+	if err := in.resolve(filepath.Dir(kv.Path), values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// rejectIncludes reports the include directives of a document that is not
+// read from a file.
+func rejectIncludes(key string, v interface{}) error {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		if _, ok := vt[includeKey]; ok {
+			return fmt.Errorf("config: %s is only allowed in files, %s has no path", includeKey, key)
+		}
+		for _, sub := range vt {
+			if err := rejectIncludes(key, sub); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, sub := range vt {
+			if err := rejectIncludes(key, sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve replaces the include directives found in v, dir is the directory
+// of the file v was decoded from.
+func (in *includer) resolve(dir string, v interface{}) error {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for _, sub := range vt {
+			if err := in.resolve(dir, sub); err != nil {
+				return err
+			}
+		}
+		names, ok := vt[includeKey]
+		if !ok {
+			return nil
+		}
+		delete(vt, includeKey)
+		return in.include(dir, names, vt)
+	case []interface{}:
+		for _, sub := range vt {
+			if err := in.resolve(dir, sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// include merges the files named by names under the keys of dst.
+func (in *includer) include(dir string, names interface{}, dst map[string]interface{}) error {
+	var files []string
+	switch nt := names.(type) {
+	case string:
+		files = []string{nt}
+	case []interface{}:
+		for _, name := range nt {
+			s, ok := name.(string)
+			if !ok {
+				return fmt.Errorf("config: %s expects file names, got: %v", includeKey, name)
+			}
+			files = append(files, s)
+		}
+	default:
+		return fmt.Errorf("config: %s expects file names, got: %v", includeKey, names)
+	}
+	merged := make(map[string]interface{})
+	for _, name := range files {
+		values, err := in.load(dir, name)
+		if err != nil {
+			return err
+		}
+		if err := in.r.opts.merge(&merged, values); err != nil {
+			return err
+		}
+	}
+	// the keys next to the directive override the included ones
+	if err := in.r.opts.merge(&merged, copyValue(dst)); err != nil {
+		return err
+	}
+	for k, v := range merged {
+		dst[k] = v
+	}
+	return nil
+}
+
+func (in *includer) load(dir, name string) (map[string]interface{}, error) {
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range in.stack {
+		if p == path {
+			return nil, fmt.Errorf("%w: %s -> %s", ErrIncludeCycle, strings.Join(in.stack, " -> "), path)
+		}
+	}
+	if len(in.stack) > maxIncludeDepth {
+		return nil, fmt.Errorf("config: include %s exceeds the depth limit of %d", path, maxIncludeDepth)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format := strings.TrimPrefix(filepath.Ext(path), ".")
+	if format == "yml" {
+		format = "yaml"
+	}
+	in.stack = append(in.stack, path)
+	defer func() { in.stack = in.stack[:len(in.stack)-1] }()
+	return in.decode(&KeyValue{Key: filepath.Base(path), Value: data, Format: format, Path: path})
+}
+
+// rewriteIncludeTags rewrites the yaml nodes tagged !include into the
+// includeKey form every format understands.
+func rewriteIncludeTags(data []byte) ([]byte, error) {
+	if !strings.Contains(string(data), includeTag) {
+		return data, nil
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	var rewrite func(n *yaml.Node)
+	rewrite = func(n *yaml.Node) {
+		if n.Tag == includeTag {
+			value := *n
+			value.Tag = ""
+			if value.Kind == yaml.ScalarNode {
+				value.Tag = "!!str"
+			}
+			*n = yaml.Node{
+				Kind: yaml.MappingNode,
+				Tag:  "!!map",
+				Content: []*yaml.Node{
+					{Kind: yaml.ScalarNode, Tag: "!!str", Value: includeKey},
+					&value,
+				},
+			}
+			return
+		}
+		for _, c := range n.Content {
+			rewrite(c)
+		}
+	}
+	rewrite(&root)
+	return yaml.Marshal(&root)
+}
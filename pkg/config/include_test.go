@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeIncludeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func loadIncludeFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := New(WithSource(&testStaticSource{kvs: []*KeyValue{{
+		Key:    filepath.Base(path),
+		Value:  data,
+		Format: strings.TrimPrefix(filepath.Ext(path), "."),
+		Path:   path,
+	}}}))
+	return c, c.Load()
+}
+
+func TestInclude(t *testing.T) {
+	dir := writeIncludeFiles(t, map[string]string{
+		"base/server.yaml": `
+$include: logging.json
+server:
+  addr: 0.0.0.0
+  port: 80
+`,
+		"base/logging.json": `{"log":{"level":"info","format":"json"}}`,
+		"base/db.yaml":      "host: db.local\nport: 3306",
+		"app.yaml": `
+$include: [base/server.yaml]
+server:
+  port: 8080
+log:
+  level: debug
+database: !include base/db.yaml
+`,
+	})
+	c, err := loadIncludeFile(filepath.Join(dir, "app.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for key, want := range map[string]string{
+		"server.addr":   "0.0.0.0",
+		"server.port":   "8080",
+		"log.level":     "debug",
+		"log.format":    "json",
+		"database.host": "db.local",
+		"database.port": "3306",
+	} {
+		if got, err := c.Value(key).String(); err != nil || got != want {
+			t.Errorf("%s want: %s, got: %s (%v)", key, want, got, err)
+		}
+	}
+	if _, err := c.Value(includeKey).String(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("%s should be removed, got error: %v", includeKey, err)
+	}
+}
+
+func TestIncludeCycle(t *testing.T) {
+	dir := writeIncludeFiles(t, map[string]string{
+		"a.yaml":     "$include: b.yaml\na: 1",
+		"b.yaml":     "$include: sub/c.json\nb: 1",
+		"sub/c.json": `{"$include":"../a.yaml"}`,
+	})
+	if _, err := loadIncludeFile(filepath.Join(dir, "a.yaml")); !errors.Is(err, ErrIncludeCycle) {
+		t.Errorf("want ErrIncludeCycle, got: %v", err)
+	}
+}
+
+func TestIncludeDepth(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i <= maxIncludeDepth+1; i++ {
+		files[strings.Repeat("x", i+1)+".yaml"] = "$include: " + strings.Repeat("x", i+2) + ".yaml"
+	}
+	files[strings.Repeat("x", maxIncludeDepth+3)+".yaml"] = "end: true"
+	dir := writeIncludeFiles(t, files)
+	_, err := loadIncludeFile(filepath.Join(dir, "x.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "depth limit") {
+		t.Errorf("want depth limit error, got: %v", err)
+	}
+}
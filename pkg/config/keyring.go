@@ -0,0 +1,213 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// KeyRingEnv is the variable KeyRingFromEnv reads the key ring from.
+	KeyRingEnv = "PETAL_CONFIG_KEYS"
+	// encAlgorithm is the only algorithm of encrypted values, ENC[aes256gcm:id:data].
+	encAlgorithm = "aes256gcm"
+	encKeySize   = 32
+)
+
+var (
+	// ErrNoKeyRing is returned when an encrypted value is resolved without a key ring.
+	ErrNoKeyRing = errors.New("config: no key ring to decrypt ENC values")
+	// ErrUnknownKey is returned when a value was encrypted with a key missing from the key ring.
+	ErrUnknownKey = errors.New("config: unknown key id")
+
+	encRegexp = regexp.MustCompile(`ENC\[([a-z0-9]+):([^:\]]+):([A-Za-z0-9+/=]+)\]`)
+)
+
+// WithKeyRing decrypts the ENC[aes256gcm:id:data] values with the keys of k
+// during Resolve, the decrypted values are redacted like resolved secrets.
+func WithKeyRing(k *KeyRing) Option {
+	return func(o *options) {
+		o.secrets.keys = k
+	}
+}
+
+// WithEncryptedRedacted loads the ENC values as Redacted when no key ring is
+// set instead of failing with ErrNoKeyRing, for the tools printing a config
+// they cannot decrypt.
+func WithEncryptedRedacted() Option {
+	return func(o *options) {
+		o.secrets.redactEncrypted = true
+	}
+}
+
+// KeyRing holds the AES-256 keys of encrypted values by id. Values are
+// encrypted with the primary key and decrypted with the key of their id,
+// so a key can be rotated by adding a new primary key and keeping the old
+// ones until every value is encrypted again.
+type KeyRing struct {
+	primary string
+	keys    map[string][]byte
+}
+
+// NewKeyRing new an empty key ring.
+func NewKeyRing() *KeyRing {
+	return &KeyRing{keys: make(map[string][]byte)}
+}
+
+// ParseKeyRing parses one key per line or comma separated entry in the form
+// id:base64-key, the first key is the primary key. Blank lines and lines
+// starting with # are ignored.
+func ParseKeyRing(data []byte) (*KeyRing, error) {
+	k := NewKeyRing()
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, field := range strings.Split(line, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			id, encoded, ok := strings.Cut(field, ":")
+			if !ok {
+				return nil, fmt.Errorf("config: key ring entry %q is not id:key", id)
+			}
+			key, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("config: key %s: %w", id, err)
+			}
+			if err := k.Add(id, key); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// LoadKeyRing loads the key ring from the file path, see ParseKeyRing.
+func LoadKeyRing(path string) (*KeyRing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyRing(data)
+}
+
+// KeyRingFromEnv loads the key ring from the variable name, KeyRingEnv if name is empty.
+func KeyRingFromEnv(name string) (*KeyRing, error) {
+	if name == "" {
+		name = KeyRingEnv
+	}
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("config: environment variable %s is not set", name)
+	}
+	return ParseKeyRing([]byte(v))
+}
+
+// Add adds key under id, the first key added is the primary key.
+func (k *KeyRing) Add(id string, key []byte) error {
+	if id == "" || strings.ContainsAny(id, ":],") {
+		return fmt.Errorf("config: invalid key id %q", id)
+	}
+	if len(key) != encKeySize {
+		return fmt.Errorf("config: key %s must be %d bytes", id, encKeySize)
+	}
+	if _, ok := k.keys[id]; ok {
+		return fmt.Errorf("config: duplicate key id %s", id)
+	}
+	k.keys[id] = key
+	if k.primary == "" {
+		k.primary = id
+	}
+	return nil
+}
+
+// SetPrimary makes the key id the key new values are encrypted with.
+func (k *KeyRing) SetPrimary(id string) error {
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	k.primary = id
+	return nil
+}
+
+// Primary returns the id of the primary key.
+func (k *KeyRing) Primary() string {
+	return k.primary
+}
+
+// Encrypt encrypts plain with the primary key into ENC[aes256gcm:id:data].
+func (k *KeyRing) Encrypt(plain string) (string, error) {
+	if k.primary == "" {
+		return "", errors.New("config: empty key ring")
+	}
+	aead, err := newGCM(k.keys[k.primary])
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plain), []byte(k.primary))
+	return fmt.Sprintf("ENC[%s:%s:%s]", encAlgorithm, k.primary, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt.
+func (k *KeyRing) Decrypt(value string) (string, error) {
+	m := encRegexp.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil || m[0] != strings.TrimSpace(value) {
+		return "", fmt.Errorf("config: %q is not an ENC value", value)
+	}
+	return k.decrypt(m[1], m[2], m[3])
+}
+
+func (k *KeyRing) decrypt(algorithm, id, data string) (string, error) {
+	if algorithm != encAlgorithm {
+		return "", fmt.Errorf("config: unsupported algorithm %s", algorithm)
+	}
+	key, ok := k.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("config: encrypted value too short")
+	}
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	// the key id is authenticated so a value cannot be moved to another key
+	plain, err := aead.Open(nil, nonce, sealed, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("config: decrypt with key %s: %w", id, err)
+	}
+	return string(plain), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
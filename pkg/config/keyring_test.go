@@ -0,0 +1,106 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, encKeySize))
+}
+
+func TestKeyRing(t *testing.T) {
+	old, err := ParseKeyRing([]byte("# old key\nk1:" + testKey(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := old.Encrypt("s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(enc, "ENC[aes256gcm:k1:") {
+		t.Fatalf("unexpected encrypted value: %s", enc)
+	}
+
+	// rotated: k2 is the primary key, k1 still decrypts the old values
+	k, err := ParseKeyRing([]byte("k2:" + testKey(2) + ", k1:" + testKey(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := k.Decrypt(enc); err != nil || got != "s3cr3t" {
+		t.Errorf("Decrypt() = %q, %v", got, err)
+	}
+	rotated, err := k.Encrypt("s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rotated, "ENC[aes256gcm:k2:") {
+		t.Errorf("want the primary key k2, got: %s", rotated)
+	}
+	if _, err := old.Decrypt(rotated); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("want ErrUnknownKey, got: %v", err)
+	}
+
+	// the key id is authenticated
+	moved := strings.Replace(enc, ":k1:", ":k2:", 1)
+	if _, err := k.Decrypt(moved); err == nil {
+		t.Error("want error for a value moved to another key")
+	}
+
+	for _, data := range []string{"k1", "k1:not-base64!", "k1:" + base64.StdEncoding.EncodeToString([]byte("short")), "k1:" + testKey(1) + "\nk1:" + testKey(2)} {
+		if _, err := ParseKeyRing([]byte(data)); err == nil {
+			t.Errorf("ParseKeyRing(%q) expect error", data)
+		}
+	}
+}
+
+func TestKeyRingResolve(t *testing.T) {
+	t.Setenv("KEYRING_TEST_KEYS", "k1:"+testKey(1))
+	k, err := KeyRingFromEnv("KEYRING_TEST_KEYS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := k.Encrypt("p@ss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := &testStaticSource{kvs: []*KeyValue{{
+		Key:    "app.json",
+		Format: "json",
+		Value:  []byte(`{"db": {"password": "` + enc + `", "dsn": "root:${db.password}@db"}}`),
+	}}}
+
+	c := New(WithSource(source), WithKeyRing(k))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if got, _ := c.Value("db.dsn").String(); got != "root:p@ss@db" {
+		t.Errorf("db.dsn = %q", got)
+	}
+	data, err := c.(*config).reader.Source()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "p@ss") {
+		t.Errorf("Source() leaks the decrypted value: %s", data)
+	}
+
+	c = New(WithSource(source))
+	defer c.Close()
+	if err := c.Load(); !errors.Is(err, ErrNoKeyRing) {
+		t.Errorf("want ErrNoKeyRing, got: %v", err)
+	}
+
+	c = New(WithSource(source), WithEncryptedRedacted())
+	defer c.Close()
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.Value("db.dsn").String(); got != "root:"+Redacted+"@db" {
+		t.Errorf("db.dsn = %q", got)
+	}
+}
@@ -0,0 +1,90 @@
+package kv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/bytedance/sonic"
+)
+
+var _ Backend = (*File)(nil)
+
+// File is a Memory persisted to a single file, a local store for machines
+// without a remote one. Every Put and Delete rewrites the file through a
+// rename, the history is not persisted so watches can only start at the
+// revision the file was opened at or later. The file is owned by one
+// process, changes made by other processes are not seen until it is reopened.
+type File struct {
+	*Memory
+	path string
+	// saving serializes the changes with the writes of the file
+	saving sync.Mutex
+}
+
+// fileState is the content of the file.
+type fileState struct {
+	Revision int64   `json:"revision"`
+	Pairs    []*Pair `json:"pairs"`
+}
+
+// OpenFile opens the store at path, the file is created by the first change.
+func OpenFile(path string) (*File, error) {
+	f := &File{Memory: NewMemory(), path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := &fileState{}
+	if err := sonic.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	for _, p := range state.Pairs {
+		f.pairs[p.Key] = p
+	}
+	f.revision, f.compacted = state.Revision, state.Revision
+	return f, nil
+}
+
+// Put sets the value of key, writes the file and returns the new revision.
+func (f *File) Put(key string, value []byte) (int64, error) {
+	f.saving.Lock()
+	defer f.saving.Unlock()
+	rev := f.Memory.Put(key, value)
+	return rev, f.save()
+}
+
+// Delete deletes key, writes the file and returns the new revision.
+func (f *File) Delete(key string) (int64, error) {
+	f.saving.Lock()
+	defer f.saving.Unlock()
+	rev := f.Memory.Delete(key)
+	return rev, f.save()
+}
+
+func (f *File) save() error {
+	f.lock.Lock()
+	state := &fileState{Revision: f.revision, Pairs: f.list("")}
+	f.lock.Unlock()
+	data, err := sonic.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
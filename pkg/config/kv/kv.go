@@ -0,0 +1,57 @@
+// Package kv provides a config source reading a key prefix of a key-value
+// store. Stores are plugged in through the Backend interface, Memory and
+// File are the built-in ones.
+package kv
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCompacted is sent by a watch started at a revision the backend no
+// longer keeps the history of, the source reloads the prefix and watches
+// again from the revision of the reload.
+var ErrCompacted = errors.New("kv: revision compacted")
+
+// Pair is a key and its value.
+type Pair struct {
+	Key   string
+	Value []byte
+	// Revision is the revision of the store the pair was last modified at.
+	Revision int64
+}
+
+// EventType is the type of Event.
+type EventType int
+
+const (
+	// EventPut is sent when a key is created or updated.
+	EventPut EventType = iota
+	// EventDelete is sent when a key is deleted, the value of the pair is empty.
+	EventDelete
+)
+
+// Event is a change of a key.
+type Event struct {
+	Type EventType
+	Pair
+}
+
+// WatchResponse is a batch of events, Revision is the revision of the store
+// after the events. A response with Err set is the last one of the watch.
+type WatchResponse struct {
+	Revision int64
+	Events   []*Event
+	Err      error
+}
+
+// Backend is a key-value store with revisions, like etcd or consul.
+type Backend interface {
+	// Get returns the pairs with keys under prefix and the revision of the
+	// store they were read at. prefix is a plain key prefix, the source
+	// passes its prefix ending with the separator.
+	Get(ctx context.Context, prefix string) ([]*Pair, int64, error)
+	// Watch sends the changes of the keys under prefix made after revision,
+	// in revision order. The channel is closed when ctx is done.
+	Watch(ctx context.Context, prefix string, revision int64) (<-chan *WatchResponse, error)
+}
@@ -0,0 +1,153 @@
+package kv
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var _ Backend = (*Memory)(nil)
+
+// Memory is an in-memory Backend for tests and local runs. It keeps the
+// events since the last Compact, so watches can start at any later revision.
+type Memory struct {
+	lock      sync.Mutex
+	revision  int64
+	compacted int64
+	pairs     map[string]*Pair
+	history   []*Event
+	// changed is closed and replaced on every change
+	changed chan struct{}
+}
+
+// NewMemory new an empty Memory at revision 0.
+func NewMemory() *Memory {
+	return &Memory{pairs: make(map[string]*Pair), changed: make(chan struct{})}
+}
+
+// Get returns the pairs whose keys start with prefix, like a prefix range of
+// etcd, the source adds the separator to its prefix.
+func (m *Memory) Get(ctx context.Context, prefix string) ([]*Pair, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.list(prefix), m.revision, nil
+}
+
+// list returns copies of the pairs under prefix in key order, m.lock must be held.
+func (m *Memory) list(prefix string) []*Pair {
+	var pairs []*Pair
+	for k, p := range m.pairs {
+		if strings.HasPrefix(k, prefix) {
+			cp := *p
+			pairs = append(pairs, &cp)
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Key < pairs[j].Key })
+	return pairs
+}
+
+// Put sets the value of key and returns the new revision of the store.
+func (m *Memory) Put(key string, value []byte) int64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	p := &Pair{Key: key, Value: append([]byte(nil), value...), Revision: m.revision + 1}
+	m.pairs[key] = p
+	m.record(&Event{Type: EventPut, Pair: *p})
+	return m.revision
+}
+
+// Delete deletes key and returns the new revision of the store, the
+// revision does not change when key does not exist.
+func (m *Memory) Delete(key string) int64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.pairs[key]; !ok {
+		return m.revision
+	}
+	delete(m.pairs, key)
+	m.record(&Event{Type: EventDelete, Pair: Pair{Key: key, Revision: m.revision + 1}})
+	return m.revision
+}
+
+// record appends e to the history and wakes up the watches, m.lock must be held.
+func (m *Memory) record(e *Event) {
+	m.revision = e.Revision
+	m.history = append(m.history, e)
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// Compact drops the events up to revision, watches started before it
+// receive ErrCompacted.
+func (m *Memory) Compact(revision int64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if revision > m.revision {
+		revision = m.revision
+	}
+	if revision <= m.compacted {
+		return
+	}
+	m.compacted = revision
+	i := sort.Search(len(m.history), func(i int) bool { return m.history[i].Revision > revision })
+	m.history = append([]*Event(nil), m.history[i:]...)
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+func (m *Memory) Watch(ctx context.Context, prefix string, revision int64) (<-chan *WatchResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ch := make(chan *WatchResponse)
+	go func() {
+		defer close(ch)
+		for {
+			m.lock.Lock()
+			if revision < m.compacted {
+				resp := &WatchResponse{Revision: m.revision, Err: ErrCompacted}
+				m.lock.Unlock()
+				select {
+				case ch <- resp:
+				case <-ctx.Done():
+				}
+				return
+			}
+			events := m.since(prefix, revision)
+			current, changed := m.revision, m.changed
+			m.lock.Unlock()
+
+			if len(events) > 0 {
+				select {
+				case ch <- &WatchResponse{Revision: current, Events: events}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			revision = current
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// since returns copies of the events under prefix after revision, m.lock must be held.
+func (m *Memory) since(prefix string, revision int64) []*Event {
+	i := sort.Search(len(m.history), func(i int) bool { return m.history[i].Revision > revision })
+	var events []*Event
+	for _, e := range m.history[i:] {
+		if strings.HasPrefix(e.Key, prefix) {
+			cp := *e
+			events = append(events, &cp)
+		}
+	}
+	return events
+}
@@ -0,0 +1,111 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func recv(t *testing.T, ch <-chan *WatchResponse) *WatchResponse {
+	t.Helper()
+	select {
+	case resp := <-ch:
+		return resp
+	case <-time.After(time.Second):
+		t.Fatal("no watch response")
+		return nil
+	}
+}
+
+func TestMemoryWatch(t *testing.T) {
+	m := NewMemory()
+	m.Put("/app/a", []byte("1"))
+	m.Put("/other/a", []byte("1"))
+	rev := m.Put("/app/b", []byte("2"))
+
+	pairs, got, err := m.Get(context.Background(), "/app/")
+	if err != nil || got != rev || len(pairs) != 2 || pairs[0].Key != "/app/a" || pairs[1].Revision != rev {
+		t.Fatalf("Get() = %+v %d %v", pairs, got, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// started in the past, the history is replayed
+	ch, err := m.Watch(ctx, "/app/", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := recv(t, ch)
+	if resp.Revision != rev || len(resp.Events) != 1 || resp.Events[0].Key != "/app/b" {
+		t.Fatalf("unexpected replay %+v", resp)
+	}
+
+	m.Put("/other/b", []byte("1"))
+	m.Delete("/app/a")
+	resp = recv(t, ch)
+	if len(resp.Events) != 1 || resp.Events[0].Type != EventDelete || resp.Events[0].Key != "/app/a" {
+		t.Fatalf("unexpected events %+v", resp.Events)
+	}
+
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Fatal("channel not closed after cancel")
+	}
+}
+
+func TestMemoryCompact(t *testing.T) {
+	m := NewMemory()
+	m.Put("a", []byte("1"))
+	m.Put("a", []byte("2"))
+	m.Compact(2)
+
+	ch, err := m.Watch(context.Background(), "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp := recv(t, ch); !errors.Is(resp.Err, ErrCompacted) {
+		t.Fatalf("expected ErrCompacted, got %+v", resp)
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("channel not closed after an error")
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.db")
+	f, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Put("/app/a", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Put("/app/b", []byte("2")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Delete("/app/a"); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err = OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairs, rev, err := f.Get(context.Background(), "/app/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev != 3 || len(pairs) != 1 || pairs[0].Key != "/app/b" || string(pairs[0].Value) != "2" {
+		t.Fatalf("Get() = %+v %d", pairs, rev)
+	}
+	// the history before opening is gone
+	ch, err := f.Watch(context.Background(), "/app/", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp := recv(t, ch); !errors.Is(resp.Err, ErrCompacted) {
+		t.Fatalf("expected ErrCompacted, got %+v", resp)
+	}
+}
@@ -0,0 +1,22 @@
+package kv
+
+import "time"
+
+const defaultTimeout = 10 * time.Second
+
+// Option is kv source option.
+type Option func(*source)
+
+// WithSeparator splits the keys into nested config keys at sep, the default is /.
+func WithSeparator(sep string) Option {
+	return func(s *source) {
+		s.separator = sep
+	}
+}
+
+// WithTimeout bounds the Get requests to the backend, the default is 10s.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *source) {
+		s.timeout = timeout
+	}
+}
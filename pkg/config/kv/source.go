@@ -0,0 +1,179 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/banbridge/common/pkg/config"
+	"github.com/banbridge/common/pkg/encoding"
+)
+
+var _ config.Source = (*source)(nil)
+
+type source struct {
+	backend   Backend
+	prefix    string
+	separator string
+	timeout   time.Duration
+
+	lock sync.Mutex
+	last *snapshot
+}
+
+// snapshot is the state of the prefix at a revision.
+type snapshot struct {
+	revision int64
+	values   map[string][]byte
+}
+
+// NewSource new a source reading the keys under prefix of backend. The keys
+// are split at / into nested config keys, the prefix trimmed:
+//
+//	prefix/server/port       the value of server.port
+//	prefix/db/primary.yaml   a yaml document merged into db
+//	prefix/base.json         a json document merged into the root
+//
+// Documents are keys with the extension of a codec registered in pkg/encoding.
+func NewSource(backend Backend, prefix string, opts ...Option) config.Source {
+	s := &source{
+		backend:   backend,
+		prefix:    prefix,
+		separator: "/",
+		timeout:   defaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *source) Load() ([]*config.KeyValue, error) {
+	snap, err := s.get(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	kvs, err := s.keyValues(snap.values)
+	if err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	s.last = snap
+	s.lock.Unlock()
+	return kvs, nil
+}
+
+// loaded returns the snapshot of the last Load.
+func (s *source) loaded() *snapshot {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.last
+}
+
+func (s *source) Watch() (config.Watcher, error) {
+	return newWatcher(s), nil
+}
+
+func (s *source) get(ctx context.Context) (*snapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	pairs, rev, err := s.backend.Get(ctx, s.dir())
+	if err != nil {
+		return nil, err
+	}
+	snap := &snapshot{revision: rev, values: make(map[string][]byte, len(pairs))}
+	for _, p := range pairs {
+		if s.under(p.Key) {
+			snap.values[p.Key] = p.Value
+		}
+	}
+	return snap, nil
+}
+
+func (s *source) keyValues(values map[string][]byte) ([]*config.KeyValue, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]*config.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		kv, err := s.keyValue(k, values[k])
+		if err != nil {
+			return nil, err
+		}
+		if kv != nil {
+			kvs = append(kvs, kv)
+		}
+	}
+	return kvs, nil
+}
+
+// dir returns the prefix ending with the separator, the key prefix asked to
+// the backend so that the sibling prefixes are not read: app/ and not app2.
+func (s *source) dir() string {
+	if s.prefix == "" {
+		return ""
+	}
+	return strings.TrimSuffix(s.prefix, s.separator) + s.separator
+}
+
+// under reports whether key is the prefix or a key under it.
+func (s *source) under(key string) bool {
+	return key == s.prefix || strings.HasPrefix(key, s.dir())
+}
+
+// relKey returns key without the prefix and the separators around it.
+func (s *source) relKey(key string) string {
+	return strings.Trim(strings.TrimPrefix(key, s.prefix), s.separator)
+}
+
+// keyValue encodes value as a json document nesting it under the config
+// keys of key, nil is returned for the key of the prefix itself.
+func (s *source) keyValue(key string, value []byte) (*config.KeyValue, error) {
+	rel := s.relKey(key)
+	var elems []string
+	for _, e := range strings.Split(rel, s.separator) {
+		if e != "" {
+			elems = append(elems, e)
+		}
+	}
+	if len(elems) == 0 {
+		return nil, nil
+	}
+	var v interface{} = string(value)
+	if last := elems[len(elems)-1]; docFormat(last) != "" {
+		doc := make(map[string]interface{})
+		if err := encoding.GetCodec(docFormat(last)).Unmarshal(value, &doc); err != nil {
+			return nil, fmt.Errorf("kv: decode %s: %w", key, err)
+		}
+		v, elems = doc, elems[:len(elems)-1]
+	}
+	for i := len(elems) - 1; i >= 0; i-- {
+		v = map[string]interface{}{elems[i]: v}
+	}
+	// sorted keys, the same value always encodes to the same document
+	data, err := sonic.ConfigStd.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &config.KeyValue{Key: rel, Value: data, Format: "json"}, nil
+}
+
+// docFormat returns the codec of a key element with a known extension.
+func docFormat(elem string) string {
+	ext := strings.TrimPrefix(path.Ext(elem), ".")
+	if ext == "yml" {
+		ext = "yaml"
+	}
+	if ext == "" || encoding.GetCodec(ext) == nil {
+		return ""
+	}
+	return ext
+}
@@ -0,0 +1,173 @@
+package kv
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banbridge/common/pkg/config"
+)
+
+func next(t *testing.T, w config.Watcher) []*config.KeyValue {
+	t.Helper()
+	type result struct {
+		kvs []*config.KeyValue
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		kvs, err := w.Next()
+		done <- result{kvs, err}
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			t.Fatal(r.err)
+		}
+		return r.kvs
+	case <-time.After(time.Second):
+		t.Fatal("Next did not return")
+		return nil
+	}
+}
+
+func TestLoad(t *testing.T) {
+	m := NewMemory()
+	m.Put("/app", []byte("ignored"))
+	m.Put("/app/server/port", []byte("8080"))
+	m.Put("/app/db/primary.yaml", []byte("host: db\nport: 5432"))
+	m.Put("/app/base.json", []byte(`{"name":"petal"}`))
+	m.Put("/apps/other", []byte("1"))
+
+	kvs, err := NewSource(m, "/app/").Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*config.KeyValue{
+		{Key: "base.json", Value: []byte(`{"name":"petal"}`), Format: "json"},
+		{Key: "db/primary.yaml", Value: []byte(`{"db":{"host":"db","port":5432}}`), Format: "json"},
+		{Key: "server/port", Value: []byte(`{"server":{"port":"8080"}}`), Format: "json"},
+	}
+	if !reflect.DeepEqual(kvs, want) {
+		for _, kv := range kvs {
+			t.Logf("%s %s", kv.Key, kv.Value)
+		}
+		t.Fatal("unexpected kvs")
+	}
+
+	m.Put("/app/bad.json", []byte("{"))
+	if _, err := NewSource(m, "/app/").Load(); err == nil {
+		t.Fatal("expected a decode error")
+	}
+}
+
+func TestSourceWithConfig(t *testing.T) {
+	m := NewMemory()
+	m.Put("config/app/name", []byte("petal"))
+	m.Put("config/app/port", []byte("8080"))
+
+	c := config.New(config.WithSource(NewSource(m, "config")))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if v, err := c.Value("app.port").Int(); err != nil || v != 8080 {
+		t.Fatalf("app.port = %d %v", v, err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	m := NewMemory()
+	m.Put("/app/a", []byte("1"))
+	m.Put("/app/b", []byte("2"))
+
+	s := NewSource(m, "/app/")
+	if _, err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	m.Put("/app/a", []byte("1")) // unchanged value
+	m.Put("/app/c", []byte("3"))
+	kvs := next(t, w)
+	want := []*config.KeyValue{{Key: "c", Value: []byte(`{"c":"3"}`), Format: "json"}}
+	if !reflect.DeepEqual(kvs, want) {
+		t.Fatalf("Next() = %+v", kvs)
+	}
+
+	m.Delete("/app/b")
+	kvs = next(t, w)
+	want = []*config.KeyValue{{Key: "b", Deleted: true}}
+	if !reflect.DeepEqual(kvs, want) {
+		t.Fatalf("Next() = %+v", kvs)
+	}
+}
+
+func TestSiblingPrefix(t *testing.T) {
+	m := NewMemory()
+	m.Put("app/a", []byte("1"))
+	m.Put("app2/x", []byte("2"))
+
+	s := NewSource(m, "app")
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*config.KeyValue{{Key: "a", Value: []byte(`{"a":"1"}`), Format: "json"}}
+	if !reflect.DeepEqual(kvs, want) {
+		t.Fatalf("Load() = %+v", kvs)
+	}
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	m.Put("app2/y", []byte("3"))
+	m.Put("app/b", []byte("4"))
+	kvs = next(t, w)
+	want = []*config.KeyValue{{Key: "b", Value: []byte(`{"b":"4"}`), Format: "json"}}
+	if !reflect.DeepEqual(kvs, want) {
+		t.Fatalf("Next() = %+v", kvs)
+	}
+}
+
+func TestWatchCompacted(t *testing.T) {
+	m := NewMemory()
+	m.Put("/app/a", []byte("1"))
+	m.Put("/app/b", []byte("2"))
+
+	s := NewSource(m, "/app/")
+	if _, err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// the changes made before the watch starts are compacted, they are
+	// read again from the store
+	m.Delete("/app/a")
+	m.Put("/app/b", []byte("3"))
+	m.Compact(m.Put("/app/c", []byte("4")))
+	kvs := next(t, w)
+	want := []*config.KeyValue{
+		{Key: "a", Deleted: true},
+		{Key: "b", Value: []byte(`{"b":"3"}`), Format: "json"},
+		{Key: "c", Value: []byte(`{"c":"4"}`), Format: "json"},
+	}
+	if !reflect.DeepEqual(kvs, want) {
+		t.Fatalf("Next() = %+v", kvs)
+	}
+
+	_ = w.Stop()
+	if _, err := w.Next(); err == nil {
+		t.Fatal("expected an error after Stop")
+	}
+}
@@ -0,0 +1,193 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/banbridge/common/pkg/config"
+)
+
+var _ config.Watcher = (*watcher)(nil)
+
+// watcher follows the changes of the prefix from the revision of the last
+// Load. When the watch fails the prefix is read again, the keys that
+// changed meanwhile are emitted and the watch restarts at the new revision.
+type watcher struct {
+	s     *source
+	snap  *snapshot
+	stale bool
+
+	ch        <-chan *WatchResponse
+	stopWatch context.CancelFunc
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newWatcher(s *source) *watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watcher{s: s, ctx: ctx, cancel: cancel}
+	if last := s.loaded(); last != nil {
+		w.snap = &snapshot{revision: last.revision, values: make(map[string][]byte, len(last.values))}
+		for k, v := range last.values {
+			w.snap.values[k] = v
+		}
+	} else {
+		w.snap = &snapshot{values: make(map[string][]byte)}
+		w.stale = true
+	}
+	return w
+}
+
+// Next blocks until keys under the prefix changed, deleted keys are
+// emitted with Deleted set.
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	for {
+		if w.ch == nil {
+			kvs, err := w.start()
+			if err != nil {
+				return nil, err
+			}
+			if len(kvs) > 0 {
+				return kvs, nil
+			}
+		}
+		select {
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		case resp, ok := <-w.ch:
+			switch {
+			case !ok:
+				w.reset()
+				if w.ctx.Err() != nil {
+					return nil, w.ctx.Err()
+				}
+				return nil, fmt.Errorf("kv: watch of %s closed", w.s.prefix)
+			case resp.Err != nil:
+				w.reset()
+				if errors.Is(resp.Err, ErrCompacted) {
+					continue
+				}
+				return nil, resp.Err
+			}
+			kvs, err := w.apply(resp)
+			if err != nil {
+				w.reset()
+				return nil, err
+			}
+			if len(kvs) > 0 {
+				return kvs, nil
+			}
+		}
+	}
+}
+
+// start watches the prefix, after a failed watch it is read again first
+// and the differences with the snapshot are returned.
+func (w *watcher) start() ([]*config.KeyValue, error) {
+	var kvs []*config.KeyValue
+	if w.stale {
+		next, err := w.s.get(w.ctx)
+		if err != nil {
+			return nil, err
+		}
+		if kvs, err = w.diff(next); err != nil {
+			return nil, err
+		}
+		w.snap, w.stale = next, false
+	}
+	ctx, cancel := context.WithCancel(w.ctx)
+	ch, err := w.s.backend.Watch(ctx, w.s.dir(), w.snap.revision)
+	if err != nil {
+		cancel()
+		w.stale = true
+		return nil, err
+	}
+	w.ch, w.stopWatch = ch, cancel
+	return kvs, nil
+}
+
+// reset stops the watch, the next one starts with a reload.
+func (w *watcher) reset() {
+	if w.stopWatch != nil {
+		w.stopWatch()
+	}
+	w.ch, w.stopWatch, w.stale = nil, nil, true
+}
+
+// diff returns the key values changed from the snapshot to next.
+func (w *watcher) diff(next *snapshot) ([]*config.KeyValue, error) {
+	var kvs []*config.KeyValue
+	for k, v := range next.values {
+		if old, ok := w.snap.values[k]; ok && bytes.Equal(old, v) {
+			continue
+		}
+		kv, err := w.s.keyValue(k, v)
+		if err != nil {
+			return nil, err
+		}
+		if kv != nil {
+			kvs = append(kvs, kv)
+		}
+	}
+	for k := range w.snap.values {
+		if _, ok := next.values[k]; !ok {
+			kvs = appendDeleted(kvs, w.s.relKey(k))
+		}
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs, nil
+}
+
+// apply returns the key values changed by the events of resp, the
+// snapshot is only updated when all of them could be encoded.
+func (w *watcher) apply(resp *WatchResponse) ([]*config.KeyValue, error) {
+	var kvs []*config.KeyValue
+	// the last event of every key wins
+	last := make(map[string]*Event, len(resp.Events))
+	for _, e := range resp.Events {
+		if w.s.under(e.Key) {
+			last[e.Key] = e
+		}
+	}
+	for k, e := range last {
+		old, existed := w.snap.values[k]
+		switch {
+		case e.Type == EventDelete && existed:
+			kvs = appendDeleted(kvs, w.s.relKey(k))
+		case e.Type == EventPut && (!existed || !bytes.Equal(old, e.Value)):
+			kv, err := w.s.keyValue(k, e.Value)
+			if err != nil {
+				return nil, err
+			}
+			if kv != nil {
+				kvs = append(kvs, kv)
+			}
+		}
+	}
+	for k, e := range last {
+		if e.Type == EventDelete {
+			delete(w.snap.values, k)
+		} else {
+			w.snap.values[k] = e.Value
+		}
+	}
+	w.snap.revision = resp.Revision
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs, nil
+}
+
+func appendDeleted(kvs []*config.KeyValue, key string) []*config.KeyValue {
+	if key == "" {
+		return kvs
+	}
+	return append(kvs, &config.KeyValue{Key: key, Deleted: true})
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	return nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Layer is the priority of a config source, values from a higher layer
+// override the ones from a lower layer regardless of load order.
+type Layer int
+
+const (
+	LayerDefaults Layer = iota
+	LayerFile
+	LayerEnv
+	LayerFlags
+	LayerRemote
+)
+
+func (l Layer) String() string {
+	switch l {
+	case LayerDefaults:
+		return "defaults"
+	case LayerFile:
+		return "file"
+	case LayerEnv:
+		return "env"
+	case LayerFlags:
+		return "flags"
+	case LayerRemote:
+		return "remote"
+	}
+	return fmt.Sprintf("layer(%d)", int(l))
+}
+
+// layeredSource is a Source with its name and layer.
+type layeredSource struct {
+	Source
+	name  string
+	layer Layer
+	// replace is set for the sources of WithLayer, their KeyValues replace
+	// the document of the same Key instead of being merged over it.
+	replace bool
+}
+
+// WithLayer appends a named source to layer. Sources set by WithSource
+// are placed in LayerFile in declaration order.
+//
+// A KeyValue of a source set by WithLayer replaces the document of the same
+// Key, so the keys a watched document no longer has are removed, while the
+// KeyValues of the sources set by WithSource are merged over it.
+func WithLayer(layer Layer, name string, s Source) Option {
+	return func(o *options) {
+		o.layers = append(o.layers, &layeredSource{Source: s, name: name, layer: layer, replace: true})
+	}
+}
+
+// layeredSources returns all sources ordered from the lowest to the highest priority.
+func (o options) layeredSources() []*layeredSource {
+	all := make([]*layeredSource, 0, len(o.sources)+len(o.layers))
+	for i, s := range o.sources {
+		all = append(all, &layeredSource{Source: s, name: fmt.Sprintf("source[%d]", i), layer: LayerFile})
+	}
+	all = append(all, o.layers...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].layer < all[j].layer })
+	return all
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testStaticSource struct {
+	kvs []*KeyValue
+}
+
+func (s *testStaticSource) Load() ([]*KeyValue, error) {
+	return s.kvs, nil
+}
+
+func (s *testStaticSource) Watch() (Watcher, error) {
+	return &testUpdateWatcher{exit: make(chan struct{})}, nil
+}
+
+func TestLayerExplain(t *testing.T) {
+	yamlFile := &testStaticSource{kvs: []*KeyValue{{
+		Key:    "app.yaml",
+		Format: "yaml",
+		Value: []byte(`
+server:
+  addr: 0.0.0.0
+  port: 8000
+`),
+	}}}
+	remote := newTestUpdateSource(`{"server":{"timeout":1}}`)
+	c := New(
+		WithLayer(LayerRemote, "remote", remote),
+		WithLayer(LayerEnv, "env", &testStaticSource{kvs: []*KeyValue{{Key: "server.port", Value: []byte("9000")}}}),
+		WithLayer(LayerDefaults, "defaults", &testStaticSource{kvs: []*KeyValue{{Key: "defaults", Format: "json", Value: []byte(`{"server":{"port":80}}`)}}}),
+		WithLayer(LayerFile, "file", yamlFile),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	e, err := c.Explain("server.port")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Value != "9000" {
+		t.Errorf("server.port want: 9000, got: %v", e.Value)
+	}
+	want := []Definition{
+		{Source: "env", Layer: LayerEnv, Key: "server.port", Value: "9000"},
+		{Source: "file", Layer: LayerFile, Key: "app.yaml", Line: 4, Value: 8000},
+		{Source: "defaults", Layer: LayerDefaults, Key: "defaults", Line: 1, Value: float64(80)},
+	}
+	if !reflect.DeepEqual(e.Definitions, want) {
+		t.Errorf("definitions want: %+v, got: %+v", want, e.Definitions)
+	}
+	if w, _ := e.Winner(); w.Source != "env" {
+		t.Errorf("winner want: env, got: %s", w.Source)
+	}
+
+	// an update from a higher layer loaded first must not be overridden later
+	changed := make(chan struct{}, 1)
+	c.Subscribe("server.timeout", func(ChangeSet) { changed <- struct{}{} })
+	remote.updates <- `{"server":{"timeout":2,"port":7000}}`
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("remote update not merged")
+	}
+	if port, _ := c.Value("server.port").String(); port != "7000" {
+		t.Errorf("server.port want: 7000, got: %s", port)
+	}
+	if _, err = c.Explain("not.found"); err != ErrNotFound {
+		t.Errorf("want ErrNotFound, got: %v", err)
+	}
+}
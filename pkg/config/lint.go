@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Issue is a problem found by Lint.
+type Issue struct {
+	// Key is the KeyValue.Key the issue was found in, empty for the merged config.
+	Key string
+	// Path is the config key of the issue, empty for a whole document.
+	Path    string
+	Message string
+}
+
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.Key != "" {
+		b.WriteString(i.Key + ": ")
+	}
+	if i.Path != "" {
+		b.WriteString(i.Path + ": ")
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// Decode decodes kvs and merges them in order like the sources of a Config
+// do, the placeholders and secrets are left unresolved.
+func Decode(kvs []*KeyValue, opts ...Option) (map[string]interface{}, error) {
+	o := newOptions(opts)
+	r := newReader(o).(*reader)
+	merged := make(map[string]interface{})
+	for _, kv := range kvs {
+		values, err := r.decode(kv)
+		if err != nil {
+			return nil, fmt.Errorf("config: decode %s: %w", kv.Key, err)
+		}
+		if err := o.merge(&merged, values); err != nil {
+			return nil, fmt.Errorf("config: merge %s: %w", kv.Key, err)
+		}
+	}
+	return merged, nil
+}
+
+// Lint decodes and merges kvs like Decode and reports what a Config would
+// fail on or accept silently:
+//
+//   - documents that cannot be decoded, or include files that cannot be read
+//   - keys a later document redefines with another type, e.g. a map with a string
+//   - placeholders without default referring to keys that do not exist
+//   - placeholder cycles and required placeholders without value
+//
+// Placeholders are resolved with the default resolver, secrets are not resolved.
+func Lint(kvs []*KeyValue, opts ...Option) []Issue {
+	o := newOptions(opts)
+	r := newReader(o).(*reader)
+	var issues []Issue
+	kinds := make(map[string]definedKind)
+	merged := make(map[string]interface{})
+	for _, kv := range kvs {
+		values, err := r.decode(kv)
+		if err != nil {
+			issues = append(issues, Issue{Key: kv.Key, Message: err.Error()})
+			continue
+		}
+		issues = lintKinds(issues, kinds, kv.Key, "", values)
+		if err := o.merge(&merged, copyValue(values)); err != nil {
+			issues = append(issues, Issue{Key: kv.Key, Message: err.Error()})
+		}
+	}
+
+	var missing []Issue
+	seen := make(map[string]bool)
+	p := &placeholders{input: merged, resolved: make(map[string]interface{})}
+	p.missing = func(path, key string) {
+		if id := path + "\x00" + key; !seen[id] {
+			seen[id] = true
+			missing = append(missing, Issue{Path: path, Message: fmt.Sprintf("${%s} refers to a missing key", key)})
+		}
+	}
+	if err := p.resolve(); err != nil {
+		issues = append(issues, Issue{Message: err.Error()})
+	}
+	sort.SliceStable(missing, func(i, j int) bool { return missing[i].Path < missing[j].Path })
+	return append(issues, missing...)
+}
+
+// definedKind is the kind of value a key was first defined with.
+type definedKind struct {
+	kind string
+	key  string
+}
+
+// lintKinds reports the paths of v defined with another kind by an earlier
+// document. Null values and strings holding placeholders match any kind.
+func lintKinds(issues []Issue, kinds map[string]definedKind, key, path string, v interface{}) []Issue {
+	kind := kindOf(v)
+	if path != "" && kind != "" {
+		if prev, ok := kinds[path]; ok && prev.kind != kind {
+			issues = append(issues, Issue{
+				Key:     key,
+				Path:    path,
+				Message: fmt.Sprintf("%s overrides the %s defined in %s", kind, prev.kind, prev.key),
+			})
+		}
+		kinds[path] = definedKind{kind: kind, key: key}
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return issues
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		issues = lintKinds(issues, kinds, key, joinPath(path, quoteKey(k)), m[k])
+	}
+	return issues
+}
+
+// kindOf returns the kind of a decoded value, empty for values matching any kind.
+func kindOf(v interface{}) string {
+	switch vt := v.(type) {
+	case nil:
+		return ""
+	case map[string]interface{}:
+		return "map"
+	case []interface{}:
+		return "list"
+	case string:
+		if strings.Contains(vt, "${") {
+			return ""
+		}
+		return "string"
+	case bool:
+		return "bool"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	kvs := []*KeyValue{
+		{Key: "a.yaml", Format: "yaml", Value: []byte("db:\n  host: ${HOST}\n  port: 3306")},
+		{Key: "b.json", Format: "json", Value: []byte(`{"db":{"port":3307}}`)},
+	}
+	got, err := Decode(kvs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != "map[db:map[host:${HOST} port:3307]]" {
+		t.Fatalf("Decode() = %v", got)
+	}
+
+	kvs = append(kvs, &KeyValue{Key: "c.json", Format: "json", Value: []byte("{")})
+	if _, err := Decode(kvs); err == nil {
+		t.Fatal("expected a decode error")
+	}
+}
+
+func TestLint(t *testing.T) {
+	kvs := []*KeyValue{
+		{Key: "a.yaml", Format: "yaml", Value: []byte(`
+db:
+  host: localhost
+  port: 3306
+log: info
+url: http://${db.host}:${db.port}/${db.name}
+addr: ${HOST:0.0.0.0}
+`)},
+		{Key: "b.yaml", Format: "yaml", Value: []byte(`
+db:
+  port: ${PORT}
+log:
+  level: debug
+`)},
+		{Key: "c.json", Format: "json", Value: []byte("{")},
+	}
+	got := Lint(kvs)
+	want := []Issue{
+		{Key: "b.yaml", Path: "log", Message: "map overrides the string defined in a.yaml"},
+		{Key: "c.json", Message: got[1].Message},
+		{Path: "db.port", Message: "${PORT} refers to a missing key"},
+		{Path: "url", Message: "${db.name} refers to a missing key"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for _, issue := range got {
+			t.Log(issue)
+		}
+		t.Fatal("unexpected issues")
+	}
+
+	cycle := []*KeyValue{{Key: "a.json", Format: "json", Value: []byte(`{"a":"${b}","b":"${a}"}`)}}
+	if got := Lint(cycle); len(got) != 1 || got[0].Key != "" {
+		t.Fatalf("expected a cycle issue, got %v", got)
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"github.com/bytedance/sonic"
+
+	"github.com/banbridge/common/pkg/logs"
+)
+
+// WatchLogLevels applies the levels of the logs.Config at key of c, such as
+//
+//	log:
+//	  level: info
+//	  levels:
+//	    config: debug
+//
+// and applies them again after the merges that change a key under key, see
+// logs.Config.ApplyLevels. It fails if the initial levels cannot be applied,
+// invalid levels of a reload are logged and the previous levels are kept.
+func WatchLogLevels(c Config, key string) error {
+	apply := func() error {
+		raw, err := rawValue(c, key)
+		if err != nil {
+			return err
+		}
+		data, err := sonic.Marshal(raw)
+		if err != nil {
+			return err
+		}
+		var lc logs.Config
+		if err := sonic.Unmarshal(data, &lc); err != nil {
+			return err
+		}
+		return lc.ApplyLevels()
+	}
+	if err := apply(); err != nil {
+		return err
+	}
+	c.Subscribe(key, func(ChangeSet) {
+		if err := apply(); err != nil {
+			logs.Error("failed to reload log levels of config key %s, keep previous levels: %v", key, err)
+		}
+	})
+	return nil
+}
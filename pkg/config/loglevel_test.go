@@ -0,0 +1,53 @@
+package config
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/banbridge/common/pkg/logs"
+)
+
+func TestWatchLogLevels(t *testing.T) {
+	defer func() {
+		logs.SetLevel(slog.LevelInfo)
+		logs.SetNamedLevels(nil)
+	}()
+	src := newTestUpdateSource(`{"log":{"level":"warn","levels":{"config":"debug"}}}`)
+	c := New(WithLayer(LayerFile, "test", src))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := WatchLogLevels(c, "log"); err != nil {
+		t.Fatal(err)
+	}
+	if logs.GetLevel() != slog.LevelWarn || logs.NamedLevels()["config"] != slog.LevelDebug {
+		t.Fatalf("unexpected levels %s %v", logs.GetLevel(), logs.NamedLevels())
+	}
+
+	// a merge that does not change the log key keeps the runtime levels
+	changed := make(chan struct{}, 1)
+	c.Subscribe("app", func(ChangeSet) { changed <- struct{}{} })
+	logs.SetLevel(slog.LevelInfo)
+	src.updates <- `{"log":{"level":"warn","levels":{"config":"debug"}},"app":{"name":"x"}}`
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("app change not merged")
+	}
+	if logs.GetLevel() != slog.LevelInfo {
+		t.Errorf("levels applied again for an unrelated change: %s", logs.GetLevel())
+	}
+
+	src.updates <- `{"log":{"level":"error","levels":{"config":"bad"}}}`
+	src.updates <- `{"log":{"level":"debug","levels":{}}}`
+	deadline := time.Now().Add(time.Second)
+	for logs.GetLevel() != slog.LevelDebug && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if logs.GetLevel() != slog.LevelDebug || len(logs.NamedLevels()) != 0 {
+		t.Errorf("levels not reloaded: %s %v", logs.GetLevel(), logs.NamedLevels())
+	}
+}
@@ -2,7 +2,6 @@ package config
 
 import (
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 
@@ -23,9 +22,12 @@ type Option func(*options)
 
 type options struct {
 	sources  []Source
+	layers   []*layeredSource
 	decoder  Decoder
 	resolver Resolver
 	merge    Merge
+	secrets  *secrets
+	schema   *Schema
 }
 
 // WithSource with config source.
@@ -91,62 +93,30 @@ func defaultDecoder(src *KeyValue, target map[string]interface{}) error {
 	return fmt.Errorf("unsupported key: %s format: %s", src.Key, src.Format)
 }
 
-func newActualTypesResolver(enableConvertToType bool) func(map[string]interface{}) error {
-	return func(input map[string]interface{}) error {
-		mapper := mapper(input)
-		return resolver(input, mapper, enableConvertToType)
-	}
+// Nest expands the dotted key "aaa.bbb" into map[aaa]map[bbb]value, the
+// sources keyed by dotted names use it to build their documents.
+func Nest(key string, value interface{}) map[string]interface{} {
+	return nest(strings.Split(key, "."), value)
 }
 
-// defaultResolver resolve placeholder in map value,
-// placeholder format in ${key:default}.
-func defaultResolver(input map[string]interface{}) error {
-	mapper := mapper(input)
-	return resolver(input, mapper, false)
+// nest returns v nested under keys.
+func nest(keys []string, v interface{}) map[string]interface{} {
+	for i := len(keys) - 1; i > 0; i-- {
+		v = map[string]interface{}{keys[i]: v}
+	}
+	return map[string]interface{}{keys[0]: v}
 }
 
-func resolver(input map[string]interface{}, mapper func(name string) string, toType bool) error {
-	var resolve func(map[string]interface{}) error
-	resolve = func(sub map[string]interface{}) error {
-		for k, v := range sub {
-			switch vt := v.(type) {
-			case string:
-				sub[k] = expand(vt, mapper, toType)
-			case map[string]interface{}:
-				if err := resolve(vt); err != nil {
-					return err
-				}
-			case []interface{}:
-				for i, iface := range vt {
-					switch it := iface.(type) {
-					case string:
-						vt[i] = expand(it, mapper, toType)
-					case map[string]interface{}:
-						if err := resolve(it); err != nil {
-							return err
-						}
-					}
-				}
-				sub[k] = vt
-			}
-		}
-		return nil
+func newActualTypesResolver(enableConvertToType bool) func(map[string]interface{}) error {
+	return func(input map[string]interface{}) error {
+		return resolvePlaceholders(input, enableConvertToType)
 	}
-	return resolve(input)
 }
 
-func mapper(input map[string]interface{}) func(name string) string {
-	mapper := func(name string) string {
-		args := strings.SplitN(strings.TrimSpace(name), ":", 2) //nolint:gomnd
-		if v, has := readValue(input, args[0]); has {
-			s, _ := v.String()
-			return s
-		} else if len(args) > 1 { // default value
-			return args[1]
-		}
-		return ""
-	}
-	return mapper
+// defaultResolver resolve placeholder in map value,
+// placeholder format in ${key:default}, see resolvePlaceholders.
+func defaultResolver(input map[string]interface{}) error {
+	return resolvePlaceholders(input, false)
 }
 
 func convertToType(input string) interface{} {
@@ -177,20 +147,3 @@ func convertToType(input string) interface{} {
 	// Default to string if no other conversion succeeds
 	return input
 }
-
-func expand(s string, mapping func(string) string, toType bool) interface{} {
-	r := regexp.MustCompile(`\${(.*?)}`)
-	re := r.FindAllStringSubmatch(s, -1)
-	var ct interface{}
-	for _, i := range re {
-		if len(i) == 2 { //nolint:gomnd
-			m := mapping(i[1])
-			if toType {
-				ct = convertToType(m)
-				return ct
-			}
-			s = strings.ReplaceAll(s, i[0], m)
-		}
-	}
-	return s
-}
@@ -2,7 +2,6 @@ package config
 
 import (
 	"reflect"
-	"strings"
 	"testing"
 )
 
@@ -142,7 +141,7 @@ func TestDefaultResolver(t *testing.T) {
 		{
 			name:   "test ${foo${bar}}",
 			path:   "foo.bar.value4",
-			expect: "}",
+			expect: "",
 		},
 	}
 
@@ -358,29 +357,26 @@ func TestNewDefaultResolver(t *testing.T) {
 }
 
 func TestExpand(t *testing.T) {
+	p := &placeholders{
+		input:    map[string]interface{}{"a": "A"},
+		resolved: make(map[string]interface{}),
+	}
 	tests := []struct {
-		input   string
-		mapping func(string) string
-		want    string
+		input string
+		want  string
 	}{
 		{
 			input: "${a}",
-			mapping: func(s string) string {
-				return strings.ToUpper(s)
-			},
-			want: "A",
+			want:  "A",
 		},
 		{
 			input: "a",
-			mapping: func(s string) string {
-				return strings.ToUpper(s)
-			},
-			want: "a",
+			want:  "a",
 		},
 	}
 	for _, tt := range tests {
-		if got := expand(tt.input, tt.mapping, false); got != tt.want {
-			t.Errorf("expand() want: %s, got: %s", tt.want, got)
+		if got, err := p.expand(tt.input); err != nil || got != tt.want {
+			t.Errorf("expand() want: %s, got: %v (%v)", tt.want, got, err)
 		}
 	}
 }
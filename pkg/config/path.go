@@ -0,0 +1,189 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathElem is one element of a parsed path.
+type pathElem struct {
+	// key selects a map entry, index a list element when it is not negative.
+	key      string
+	index    int
+	wildcard bool
+}
+
+// parsePath parses the paths of Value, Watch and placeholders:
+//
+//	server.port        nested keys
+//	servers.0.host     list index, or the key "0" of a map
+//	servers[0].host    list index
+//	"api.v1".port      quoted key containing dots, also ["api.v1"] or 'api.v1'
+//	services.*.port    every element of a map or list, also services[*]
+func parsePath(path string) ([]pathElem, error) {
+	var (
+		elems []pathElem
+		i     int
+	)
+	for i < len(path) {
+		switch c := path[i]; {
+		case c == '"' || c == '\'':
+			key, n, err := unquoteKey(path[i:])
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, pathElem{key: key, index: -1})
+			i += n
+		case c == '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("config: path %q: unclosed [", path)
+			}
+			inner := path[i+1 : i+end]
+			switch {
+			case inner == "*":
+				elems = append(elems, pathElem{wildcard: true})
+			case strings.HasPrefix(inner, `"`) || strings.HasPrefix(inner, "'"):
+				key, n, err := unquoteKey(inner)
+				if err != nil || n != len(inner) {
+					return nil, fmt.Errorf("config: path %q: invalid key %s", path, inner)
+				}
+				elems = append(elems, pathElem{key: key, index: -1})
+			default:
+				idx, err := strconv.Atoi(inner)
+				if err != nil || idx < 0 {
+					return nil, fmt.Errorf("config: path %q: invalid index %s", path, inner)
+				}
+				elems = append(elems, pathElem{key: inner, index: idx})
+			}
+			i += end + 1
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			elems = append(elems, bareElem(path[i:i+end]))
+			i += end
+		}
+		// elements are separated by dots, except before [
+		if i < len(path) {
+			switch path[i] {
+			case '.':
+				i++
+				if i == len(path) {
+					return nil, fmt.Errorf("config: path %q: trailing dot", path)
+				}
+			case '[':
+			default:
+				return nil, fmt.Errorf("config: path %q: unexpected %q at %d", path, path[i], i)
+			}
+		}
+	}
+	if len(elems) == 0 {
+		return nil, fmt.Errorf("config: empty path")
+	}
+	return elems, nil
+}
+
+func bareElem(s string) pathElem {
+	if s == "*" {
+		return pathElem{wildcard: true}
+	}
+	e := pathElem{key: s, index: -1}
+	if idx, err := strconv.Atoi(s); err == nil && idx >= 0 {
+		e.index = idx
+	}
+	return e
+}
+
+// unquoteKey reads the key quoted at the start of s and returns the number
+// of bytes read, \ escapes the quote and itself.
+func unquoteKey(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case quote:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("config: unterminated quoted key %s", s)
+}
+
+// quoteKey quotes key when it cannot be written bare in a path.
+func quoteKey(key string) string {
+	if key != "" && key != "*" && !strings.ContainsAny(key, `."'[]\`) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// selectPath returns the values of elems found under v, in key order for
+// wildcards over maps.
+func selectPath(v interface{}, elems []pathElem) []interface{} {
+	if len(elems) == 0 {
+		return []interface{}{v}
+	}
+	e, rest := elems[0], elems[1:]
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		if !e.wildcard {
+			next, ok := vt[e.key]
+			if !ok {
+				return nil
+			}
+			return selectPath(next, rest)
+		}
+		keys := make([]string, 0, len(vt))
+		for k := range vt {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var found []interface{}
+		for _, k := range keys {
+			found = append(found, selectPath(vt[k], rest)...)
+		}
+		return found
+	case []interface{}:
+		if !e.wildcard {
+			if e.index < 0 || e.index >= len(vt) {
+				return nil
+			}
+			return selectPath(vt[e.index], rest)
+		}
+		var found []interface{}
+		for _, next := range vt {
+			found = append(found, selectPath(next, rest)...)
+		}
+		return found
+	}
+	return nil
+}
+
+// lookupPath reads the value of path in values, a path with wildcards
+// reads the list of the values it matches.
+func lookupPath(values map[string]interface{}, path string) (interface{}, bool) {
+	elems, err := parsePath(path)
+	if err != nil {
+		return nil, false
+	}
+	found := selectPath(values, elems)
+	for _, e := range elems {
+		if e.wildcard {
+			return found, len(found) > 0
+		}
+	}
+	if len(found) == 0 {
+		return nil, false
+	}
+	return found[0], true
+}
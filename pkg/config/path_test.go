@@ -0,0 +1,113 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want []pathElem
+	}{
+		{"a.b", []pathElem{{key: "a", index: -1}, {key: "b", index: -1}}},
+		{"foo.1.name", []pathElem{{key: "foo", index: -1}, {key: "1", index: 1}, {key: "name", index: -1}}},
+		{"foo[1].age", []pathElem{{key: "foo", index: -1}, {key: "1", index: 1}, {key: "age", index: -1}}},
+		{"m[0][2]", []pathElem{{key: "m", index: -1}, {key: "0", index: 0}, {key: "2", index: 2}}},
+		{`"api.v1".port`, []pathElem{{key: "api.v1", index: -1}, {key: "port", index: -1}}},
+		{`hosts['a.b']`, []pathElem{{key: "hosts", index: -1}, {key: "a.b", index: -1}}},
+		{`hosts."0"`, []pathElem{{key: "hosts", index: -1}, {key: "0", index: -1}}},
+		{"services.*.port", []pathElem{{key: "services", index: -1}, {wildcard: true}, {key: "port", index: -1}}},
+		{"services[*]", []pathElem{{key: "services", index: -1}, {wildcard: true}}},
+	}
+	for _, tt := range tests {
+		got, err := parsePath(tt.path)
+		if err != nil {
+			t.Errorf("parsePath(%q) error: %v", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePath(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+	for _, path := range []string{"", "a.", "a[", "a[x]", "a[-1]", `"a`, `a["b]`} {
+		if _, err := parsePath(path); err == nil {
+			t.Errorf("parsePath(%q) expect error", path)
+		}
+	}
+}
+
+func TestReadValuePath(t *testing.T) {
+	m := map[string]interface{}{
+		"foo": []interface{}{
+			map[string]interface{}{"name": "nihao", "age": 18},
+			map[string]interface{}{"name": "nihao", "age": 10},
+		},
+		"services": map[string]interface{}{
+			"b": map[string]interface{}{"port": 81},
+			"a": map[string]interface{}{"port": 80},
+			"c": map[string]interface{}{"host": "c"},
+		},
+		"api.v1": map[string]interface{}{"port": 8080},
+		"ids":    map[string]interface{}{"0": "zero"},
+	}
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{"foo.0.name", "nihao"},
+		{"foo[1].age", 10},
+		{`"api.v1".port`, 8080},
+		{`["api.v1"].port`, 8080},
+		{"ids.0", "zero"},
+		{"services.*.port", []interface{}{80, 81}},
+		{"foo[*].age", []interface{}{18, 10}},
+	}
+	for _, tt := range tests {
+		v, ok := readValue(m, tt.path)
+		if !ok {
+			t.Errorf("readValue(%q) not found", tt.path)
+			continue
+		}
+		if got := v.Load(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("readValue(%q) = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+	for _, path := range []string{"foo.2.name", "foo.name", "services.*.missing", "foo[x]"} {
+		if _, ok := readValue(m, path); ok {
+			t.Errorf("readValue(%q) expect not found", path)
+		}
+	}
+}
+
+func TestConfigValuePath(t *testing.T) {
+	c := New(WithSource(&testStaticSource{kvs: []*KeyValue{{
+		Key:    "app.json",
+		Format: "json",
+		Value: []byte(`{
+			"servers": [{"host": "a", "port": 80}, {"host": "b", "port": 81}],
+			"primary": "${servers[1].host}:${servers.1.port}",
+			"dotted": {"x.y": "${servers[0].host}"}
+		}`),
+	}}}))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if got, _ := c.Value("servers[1].host").String(); got != "b" {
+		t.Errorf("servers[1].host = %q", got)
+	}
+	if got, _ := c.Value("primary").String(); got != "b:81" {
+		t.Errorf("primary = %q", got)
+	}
+	if got, _ := c.Value(`dotted."x.y"`).String(); got != "a" {
+		t.Errorf("dotted.\"x.y\" = %q", got)
+	}
+	hosts, err := c.Value("servers.*.host").Slice()
+	if err != nil || len(hosts) != 2 {
+		t.Fatalf("servers.*.host = %v, %v", hosts, err)
+	}
+	if h, _ := hosts[1].String(); h != "b" {
+		t.Errorf("servers.*.host[1] = %q", h)
+	}
+}
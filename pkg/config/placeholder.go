@@ -0,0 +1,270 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrPlaceholderCycle is returned when placeholders refer to each other in a cycle.
+var ErrPlaceholderCycle = errors.New("config: placeholder cycle")
+
+// transforms are applied to the value of a placeholder, ${key|upper}.
+var transforms = map[string]func(string) (interface{}, error){
+	"upper": func(s string) (interface{}, error) { return strings.ToUpper(s), nil },
+	"lower": func(s string) (interface{}, error) { return strings.ToLower(s), nil },
+	"trim":  func(s string) (interface{}, error) { return strings.TrimSpace(s), nil },
+	"int": func(s string) (interface{}, error) {
+		return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	},
+	"float": func(s string) (interface{}, error) {
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	},
+	"bool": func(s string) (interface{}, error) {
+		return strconv.ParseBool(strings.TrimSpace(s))
+	},
+}
+
+// placeholders resolves the placeholders of a config map:
+//
+//	${key}              the value of key, empty if key does not exist
+//	${key:default}      default if key does not exist
+//	${key:?message}     fails with message if key does not exist or is empty
+//	${key|upper|int}    the value passed through transforms
+//	${servers[0].host}  an element of a list, see parsePath
+//	${db.${env}.host}   nested placeholders are resolved first
+//	$${key}             the literal ${key}
+//
+// A string holding a single placeholder ending with the int, float or bool
+// transform takes its type, other values stay strings.
+type placeholders struct {
+	input  map[string]interface{}
+	toType bool
+	// resolved holds the resolved strings by path, stack the paths being resolved.
+	resolved map[string]interface{}
+	stack    []string
+	// missing is called with the path being resolved and the key of a
+	// placeholder without default referring to a key that does not exist.
+	missing func(path, key string)
+}
+
+// resolvePlaceholders resolves the placeholders of the string values of
+// input, toType converts the values of single placeholders with convertToType.
+func resolvePlaceholders(input map[string]interface{}, toType bool) error {
+	p := &placeholders{input: input, toType: toType, resolved: make(map[string]interface{})}
+	return p.resolve()
+}
+
+func (p *placeholders) resolve() error {
+	input := p.input
+	// values are written back once all are resolved, so a reference
+	// always reads the unresolved value of the key it refers to
+	var writes []func()
+	var walk func(path string, v interface{}) error
+	walk = func(path string, v interface{}) error {
+		switch vt := v.(type) {
+		case map[string]interface{}:
+			for k, sub := range vt {
+				k, sub := k, sub
+				if _, ok := sub.(string); ok {
+					resolved, err := p.lookup(joinPath(path, quoteKey(k)))
+					if err != nil {
+						return err
+					}
+					writes = append(writes, func() { vt[k] = resolved })
+					continue
+				}
+				if err := walk(joinPath(path, quoteKey(k)), sub); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			for i, sub := range vt {
+				i, sub := i, sub
+				if _, ok := sub.(string); ok {
+					resolved, err := p.lookup(fmt.Sprintf("%s[%d]", path, i))
+					if err != nil {
+						return err
+					}
+					writes = append(writes, func() { vt[i] = resolved })
+					continue
+				}
+				if err := walk(fmt.Sprintf("%s[%d]", path, i), sub); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk("", input); err != nil {
+		return err
+	}
+	for _, w := range writes {
+		w()
+	}
+	return nil
+}
+
+// lookup returns the resolved value of path, nil if path does not exist.
+func (p *placeholders) lookup(path string) (interface{}, error) {
+	if v, ok := p.resolved[path]; ok {
+		return v, nil
+	}
+	v, ok := lookupPath(p.input, path)
+	if !ok {
+		return nil, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return v, nil
+	}
+	for i, visiting := range p.stack {
+		if visiting == path {
+			return nil, fmt.Errorf("%w: %s -> %s", ErrPlaceholderCycle, strings.Join(p.stack[i:], " -> "), path)
+		}
+	}
+	p.stack = append(p.stack, path)
+	resolved, err := p.expand(s)
+	p.stack = p.stack[:len(p.stack)-1]
+	if err != nil {
+		return nil, err
+	}
+	p.resolved[path] = resolved
+	return resolved, nil
+}
+
+// expand resolves the placeholders of s.
+func (p *placeholders) expand(s string) (interface{}, error) {
+	var (
+		b      strings.Builder
+		single interface{}
+	)
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "$${") {
+			b.WriteString("${")
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "${") {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		end := closingBrace(s, i+2)
+		if end < 0 {
+			// unterminated placeholders are kept as text
+			b.WriteString(s[i:])
+			break
+		}
+		inner, err := p.expand(s[i+2 : end])
+		if err != nil {
+			return nil, err
+		}
+		v, err := p.eval(toString(inner))
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 && end == len(s)-1 {
+			single = v
+		}
+		b.WriteString(toString(v))
+		i = end + 1
+	}
+	if single != nil {
+		if str, ok := single.(string); ok && p.toType {
+			return convertToType(str), nil
+		}
+		return single, nil
+	}
+	return b.String(), nil
+}
+
+// eval evaluates the expression of a placeholder without its ${ and }.
+func (p *placeholders) eval(expr string) (interface{}, error) {
+	var pipes []string
+	for {
+		i := strings.LastIndex(expr, "|")
+		if i < 0 || !isTransformName(expr[i+1:]) {
+			break
+		}
+		pipes = append([]string{expr[i+1:]}, pipes...)
+		expr = expr[:i]
+	}
+	key, def, hasDefault := strings.Cut(strings.TrimSpace(expr), ":")
+	key = strings.TrimSpace(key)
+	v, err := p.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	if message, required := strings.CutPrefix(def, "?"); hasDefault && required {
+		if v == nil || toString(v) == "" {
+			if message == "" {
+				message = "required"
+			}
+			return nil, fmt.Errorf("config: ${%s}: %s", key, message)
+		}
+	} else if v == nil {
+		v = ""
+		if hasDefault {
+			v = def
+		} else if p.missing != nil && len(p.stack) > 0 {
+			p.missing(p.stack[len(p.stack)-1], key)
+		}
+	}
+	if len(pipes) == 0 {
+		return toString(v), nil
+	}
+	for _, name := range pipes {
+		fn, ok := transforms[name]
+		if !ok {
+			return nil, fmt.Errorf("config: ${%s}: unknown transform %s", key, name)
+		}
+		if v, err = fn(toString(v)); err != nil {
+			return nil, fmt.Errorf("config: ${%s|%s}: %w", key, name, err)
+		}
+	}
+	return v, nil
+}
+
+// closingBrace returns the index of the brace closing the placeholder
+// starting before from, skipping nested placeholders, or -1.
+func closingBrace(s string, from int) int {
+	depth := 1
+	for i := from; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isTransformName reports whether s names a transform, a suffix such as
+// |b of ${key:a|b} that is not one is part of the default.
+func isTransformName(s string) bool {
+	_, ok := transforms[s]
+	return ok
+}
+
+// toString formats a resolved value, values that are not scalars format as empty.
+func toString(v interface{}) string {
+	switch vt := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return vt
+	case map[string]interface{}, []interface{}:
+		return ""
+	default:
+		av := &atomicValue{}
+		av.Store(v)
+		s, _ := av.String()
+		return s
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolvePlaceholders(t *testing.T) {
+	data := map[string]interface{}{
+		"env":    "prod",
+		"scheme": "https",
+		"db": map[string]interface{}{
+			"prod": map[string]interface{}{"host": "db.prod"},
+			"port": float64(3306),
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"host": "${db.${env}.host}", "port": "8080"},
+		},
+		"api": map[string]interface{}{
+			"url": "${scheme}://${servers[0].host}:${servers[0].port}/${env|upper}",
+		},
+		"values": map[string]interface{}{
+			"escaped": "$${env} is ${env}",
+			"port":    "${servers[0].port|int}",
+			"enabled": "${missing:TRUE|lower|bool}",
+			"dsn":     "${db.${env}.host}:${db.port}",
+			"default": "${missing:http://example.com}",
+			"upper":   "${missing:|upper}",
+			"pipe":    "${missing:a|b}",
+			"pipes":   "${missing:a|b|upper}",
+		},
+	}
+	if err := resolvePlaceholders(data, false); err != nil {
+		t.Fatal(err)
+	}
+	r := reader{values: data}
+	for path, want := range map[string]interface{}{
+		"api.url":        "https://db.prod:8080/PROD",
+		"values.escaped": "${env} is prod",
+		"values.port":    int64(8080),
+		"values.enabled": true,
+		"values.dsn":     "db.prod:3306",
+		"values.default": "http://example.com",
+		"values.upper":   "",
+		"values.pipe":    "a|b",
+		"values.pipes":   "A|B",
+	} {
+		v, ok := r.Value(path)
+		if !ok {
+			t.Errorf("%s not found", path)
+			continue
+		}
+		if got := v.Load(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s want: %#v, got: %#v", path, want, got)
+		}
+	}
+	if got := data["servers"].([]interface{})[0].(map[string]interface{})["host"]; got != "db.prod" {
+		t.Errorf("servers[0].host want: db.prod, got: %v", got)
+	}
+}
+
+func TestResolvePlaceholdersError(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  string
+		err   error
+	}{
+		{
+			name:  "required",
+			input: map[string]interface{}{"dsn": "${db.password:?db.password must be set}"},
+			want:  "db.password must be set",
+		},
+		{
+			name:  "required empty",
+			input: map[string]interface{}{"password": "", "dsn": "${password:?}"},
+			want:  "${password}: required",
+		},
+		{
+			name:  "cycle",
+			input: map[string]interface{}{"a": "${b}", "b": "x${c}", "c": "${a}"},
+			err:   ErrPlaceholderCycle,
+		},
+		{
+			name:  "self",
+			input: map[string]interface{}{"a": "${a}"},
+			err:   ErrPlaceholderCycle,
+		},
+		{
+			name:  "transform",
+			input: map[string]interface{}{"port": "abc", "v": "${port|int}"},
+			want:  "${port|int}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolvePlaceholders(tt.input, false)
+			if err == nil {
+				t.Fatal("want error")
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("want %v, got: %v", tt.err, err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("want error containing %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestResolvePlaceholdersActualTypes(t *testing.T) {
+	data := map[string]interface{}{
+		"port": "8080",
+		"host": "localhost",
+		"addr": "${host}:${port}",
+		"num":  "${port}",
+	}
+	if err := resolvePlaceholders(data, true); err != nil {
+		t.Fatal(err)
+	}
+	if data["addr"] != "localhost:8080" {
+		t.Errorf("addr want: localhost:8080, got: %#v", data["addr"])
+	}
+	if data["num"] != int64(8080) {
+		t.Errorf("num want: int64(8080), got: %#v", data["num"])
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
-	"strings"
 	"sync"
 
 	"github.com/bytedance/sonic"
@@ -24,7 +23,18 @@ type Reader interface {
 type reader struct {
 	opts   options
 	values map[string]interface{}
-	lock   sync.Mutex
+	// secrets holds the resolved secrets of values.
+	secrets *resolvedSecrets
+	entries []*entry
+	lock    sync.Mutex
+	merging sync.Mutex
+}
+
+// entry is the decoded content of one KeyValue of a layered source.
+type entry struct {
+	src    *layeredSource
+	kv     *KeyValue
+	values map[string]interface{}
 }
 
 func newReader(opts options) Reader {
@@ -41,38 +51,247 @@ func (r *reader) Merge(kvs ...*KeyValue) error {
 		return err
 	}
 	for _, kv := range kvs {
-		next := make(map[string]interface{})
-		if err := r.opts.decoder(kv, next); err != nil {
-			hlog.Errorf("Failed to config decode error: %v key: %s value: %s", err, kv.Key, string(kv.Value))
+		if kv.Deleted {
+			continue
+		}
+		next, err := r.decode(kv)
+		if err != nil {
+			hlog.Errorf("Failed to config decode error: %v key: %s value: %s", err, kv.Key, r.redactDocument(kv.Value))
 			return err
 		}
-		if err := r.opts.merge(&merged, convertMap(next)); err != nil {
-			hlog.Errorf("Failed to config merge error: %v key: %s value: %s", err, kv.Key, string(kv.Value))
+		if err := r.opts.merge(&merged, next); err != nil {
+			hlog.Errorf("Failed to config merge error: %v key: %s value: %s", err, kv.Key, r.redactDocument(kv.Value))
+			return err
+		}
+	}
+	r.lock.Lock()
+	r.values = merged
+	r.lock.Unlock()
+	return nil
+}
+
+// mergeFrom puts the entries of kvs for src and rebuilds the merged map
+// from all entries in layer order, so an update of a lower layer never
+// overrides a higher one.
+func (r *reader) mergeFrom(src *layeredSource, kvs ...*KeyValue) error {
+	r.merging.Lock()
+	defer r.merging.Unlock()
+	entries, merged, err := r.prepare(src, kvs)
+	if err != nil {
+		return err
+	}
+	r.commit(entries, merged)
+	return nil
+}
+
+// update is mergeFrom for a reload: the merged map is resolved and passed
+// to check before it replaces the current one, so a reload failing either
+// leaves the reader untouched.
+func (r *reader) update(src *layeredSource, kvs []*KeyValue, check func(map[string]interface{}) error) error {
+	r.merging.Lock()
+	defer r.merging.Unlock()
+	entries, merged, err := r.prepare(src, kvs)
+	if err != nil {
+		return err
+	}
+	secrets, err := r.resolveValues(merged)
+	if err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(merged); err != nil {
 			return err
 		}
 	}
+	r.commit(entries, merged)
+	r.lock.Lock()
+	r.secrets = secrets
+	r.lock.Unlock()
+	return nil
+}
+
+// resolveValues resolves the secrets then the placeholders of values, and
+// returns the secrets they hold once resolved.
+func (r *reader) resolveValues(values map[string]interface{}) (*resolvedSecrets, error) {
+	found := &resolvedSecrets{paths: make(secretPaths)}
+	var placeholders secretPaths
+	if r.opts.secrets != nil {
+		var err error
+		if found, placeholders, err = r.opts.secrets.resolve(values, r.opts.resolver != nil); err != nil {
+			return nil, err
+		}
+	}
+	if r.opts.resolver != nil {
+		if err := r.opts.resolver(values); err != nil {
+			return nil, err
+		}
+	}
+	// the placeholders copying a secret hold it too
+	for path := range placeholders {
+		if v, ok := lookupPath(values, path); ok && found.contains(toString(v)) {
+			found.paths[path] = struct{}{}
+		}
+	}
+	return found, nil
+}
+
+// redactDocument redacts the secrets of the last resolve from a document
+// logged with an error.
+func (r *reader) redactDocument(doc []byte) string {
+	r.lock.Lock()
+	secrets := r.secrets
+	r.lock.Unlock()
+	return secrets.redactDocument(string(doc))
+}
+
+// prepare decodes kvs and returns the entries and the merged map they
+// produce without changing the reader, r.merging must be held. The
+// documents of a source that does not replace are merged over the entry
+// of the same key.
+func (r *reader) prepare(src *layeredSource, kvs []*KeyValue) ([]*entry, map[string]interface{}, error) {
+	decoded := make([]*entry, 0, len(kvs))
+	for _, kv := range kvs {
+		if kv.Deleted {
+			decoded = append(decoded, &entry{src: src, kv: kv})
+			continue
+		}
+		next, err := r.decode(kv)
+		if err != nil {
+			hlog.Errorf("Failed to config decode error: %v key: %s value: %s", err, kv.Key, r.redactDocument(kv.Value))
+			return nil, nil, err
+		}
+		decoded = append(decoded, &entry{src: src, kv: kv, values: next})
+	}
+	r.lock.Lock()
+	entries := make([]*entry, len(r.entries))
+	copy(entries, r.entries)
+	r.lock.Unlock()
+	for _, e := range decoded {
+		if !src.replace && !e.kv.Deleted {
+			if old := findEntry(entries, e); old != nil {
+				values := copyValue(old.values).(map[string]interface{})
+				if err := r.opts.merge(&values, e.values); err != nil {
+					hlog.Errorf("Failed to config merge error: %v key: %s source: %s", err, e.kv.Key, src.name)
+					return nil, nil, err
+				}
+				e.values = values
+			}
+		}
+		entries = putEntry(entries, e)
+	}
+	merged := make(map[string]interface{})
+	for _, e := range entries {
+		if err := r.opts.merge(&merged, copyValue(e.values)); err != nil {
+			hlog.Errorf("Failed to config merge error: %v key: %s source: %s", err, e.kv.Key, e.src.name)
+			return nil, nil, err
+		}
+	}
+	return entries, merged, nil
+}
+
+func (r *reader) commit(entries []*entry, merged map[string]interface{}) {
 	r.lock.Lock()
+	r.entries = entries
 	r.values = merged
 	r.lock.Unlock()
+}
+
+// findEntry returns the entry with the source name and key of e, or nil.
+func findEntry(entries []*entry, e *entry) *entry {
+	for _, old := range entries {
+		if old.src.name == e.src.name && old.kv.Key == e.kv.Key {
+			return old
+		}
+	}
 	return nil
 }
 
+// putEntry replaces the entry with the same source name and key, or inserts e
+// after the last entry of the same or a lower layer. A deleted e removes the entry.
+func putEntry(entries []*entry, e *entry) []*entry {
+	pos := len(entries)
+	for i, old := range entries {
+		if old.src.name == e.src.name && old.kv.Key == e.kv.Key {
+			if e.kv.Deleted {
+				return append(entries[:i], entries[i+1:]...)
+			}
+			entries[i] = e
+			return entries
+		}
+		if old.src.layer > e.src.layer && pos == len(entries) {
+			pos = i
+		}
+	}
+	if e.kv.Deleted {
+		return entries
+	}
+	entries = append(entries, nil)
+	copy(entries[pos+1:], entries[pos:])
+	entries[pos] = e
+	return entries
+}
+
 func (r *reader) Value(path string) (Value, bool) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 	return readValue(r.values, path)
 }
 
+// Source returns the merged config as json, with the resolved secrets redacted.
 func (r *reader) Source() ([]byte, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return marshalJSON(r.redacted())
+}
+
+// redacted returns a copy of the merged map with the secrets redacted,
+// r.lock must be held.
+func (r *reader) redacted() interface{} {
+	return r.secrets.redact("", convertMap(r.values))
+}
+
+// source returns the merged config as json, secrets included.
+func (r *reader) source() ([]byte, error) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 	return marshalJSON(convertMap(r.values))
 }
 
+// Resolve resolves a copy of the merged map and swaps it in on success,
+// readers never see a partly resolved map.
 func (r *reader) Resolve() error {
+	return r.resolve(nil)
+}
+
+// resolve is Resolve with check run on the resolved map before the swap.
+func (r *reader) resolve(check func(map[string]interface{}) error) error {
+	r.merging.Lock()
+	defer r.merging.Unlock()
+	r.lock.Lock()
+	values := copyValue(r.values).(map[string]interface{})
+	r.lock.Unlock()
+	secrets, err := r.resolveValues(values)
+	if err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(values); err != nil {
+			return err
+		}
+	}
+	r.lock.Lock()
+	r.values = values
+	r.secrets = secrets
+	r.lock.Unlock()
+	return nil
+}
+
+// snapshot returns the current merged map, it is replaced rather than
+// modified by the next Merge and must be treated as read-only.
+func (r *reader) snapshot() map[string]interface{} {
 	r.lock.Lock()
 	defer r.lock.Unlock()
-	return r.opts.resolver(r.values)
+	return r.values
 }
 
 func (r *reader) cloneMap() (map[string]interface{}, error) {
@@ -100,6 +319,26 @@ func cloneMap(src map[string]interface{}) (map[string]interface{}, error) {
 	return clone, nil
 }
 
+// copyValue deep copies the maps and slices of a decoded value.
+func copyValue(src interface{}) interface{} {
+	switch m := src.(type) {
+	case map[string]interface{}:
+		dst := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			dst[k] = copyValue(v)
+		}
+		return dst
+	case []interface{}:
+		dst := make([]interface{}, len(m))
+		for k, v := range m {
+			dst[k] = copyValue(v)
+		}
+		return dst
+	default:
+		return src
+	}
+}
+
 func convertMap(src interface{}) interface{} {
 	switch m := src.(type) {
 	case map[string]interface{}:
@@ -130,30 +369,15 @@ func convertMap(src interface{}) interface{} {
 
 // readValue read Value in given map[string]interface{}
 // by the given path, will return false if not found.
+// A path with wildcards reads the list of the values it matches, see parsePath.
 func readValue(values map[string]interface{}, path string) (Value, bool) {
-	var (
-		next = values
-		keys = strings.Split(path, ".")
-		last = len(keys) - 1
-	)
-	for idx, key := range keys {
-		value, ok := next[key]
-		if !ok {
-			return nil, false
-		}
-		if idx == last {
-			av := &atomicValue{}
-			av.Store(value)
-			return av, true
-		}
-		switch vm := value.(type) {
-		case map[string]interface{}:
-			next = vm
-		default:
-			return nil, false
-		}
-	}
-	return nil, false
+	value, ok := lookupPath(values, path)
+	if !ok {
+		return nil, false
+	}
+	av := &atomicValue{}
+	av.Store(value)
+	return av, true
 }
 
 func marshalJSON(v interface{}) ([]byte, error) {
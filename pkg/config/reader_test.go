@@ -328,3 +328,49 @@ func TestReadValue(t *testing.T) {
 		}
 	}
 }
+
+func TestReader_MergeFromDeleted(t *testing.T) {
+	r := newReader(options{
+		decoder: defaultDecoder,
+		merge: func(dst, src interface{}) error {
+			return mergo.Map(dst, src, mergo.WithOverride)
+		},
+	}).(*reader)
+	src := &layeredSource{name: "env", layer: LayerEnv}
+	if err := r.mergeFrom(src, &KeyValue{Key: "a", Value: []byte("1")}, &KeyValue{Key: "b", Value: []byte("2")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.mergeFrom(src, &KeyValue{Key: "a", Deleted: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Value("a"); ok {
+		t.Error("deleted key a is still present")
+	}
+	if _, ok := r.Value("b"); !ok {
+		t.Error("key b is missing")
+	}
+}
+
+func TestReader_MergeFromReplace(t *testing.T) {
+	for _, replace := range []bool{false, true} {
+		r := newReader(options{
+			decoder: defaultDecoder,
+			merge: func(dst, src interface{}) error {
+				return mergo.Map(dst, src, mergo.WithOverride)
+			},
+		}).(*reader)
+		src := &layeredSource{name: "file", layer: LayerFile, replace: replace}
+		if err := r.mergeFrom(src, &KeyValue{Key: "app.json", Value: []byte(`{"a":1,"b":2}`), Format: "json"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.mergeFrom(src, &KeyValue{Key: "app.json", Value: []byte(`{"a":3}`), Format: "json"}); err != nil {
+			t.Fatal(err)
+		}
+		if v, ok := r.Value("a"); !ok || v.Load() != float64(3) {
+			t.Errorf("replace %v: a want: 3, got: %v", replace, v)
+		}
+		if _, ok := r.Value("b"); ok == replace {
+			t.Errorf("replace %v: b present: %v", replace, ok)
+		}
+	}
+}
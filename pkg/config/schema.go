@@ -0,0 +1,533 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/banbridge/common/pkg/encoding"
+)
+
+// SchemaDraft is the JSON Schema dialect of the generated schemas.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// WithSchema validates the merged config against s on Load and on every
+// watched reload, an invalid reload is rejected and the previous config kept.
+func WithSchema(s *Schema) Option {
+	return func(o *options) {
+		o.schema = s
+	}
+}
+
+// Schema is the subset of JSON Schema used to validate config: type,
+// properties, required, additionalProperties, items, enum, the numeric
+// bounds, minLength, maxLength, pattern, minItems and maxItems. Other
+// keywords are kept but not validated.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 SchemaType         `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+
+	// boolean is set for the true and false schemas.
+	boolean *bool
+}
+
+// SchemaType is the type keyword, a single type or a list of types.
+type SchemaType []string
+
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return sonic.Marshal(t[0])
+	}
+	return sonic.Marshal([]string(t))
+}
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return sonic.Unmarshal(data, (*[]string)(t))
+	}
+	var s string
+	if err := sonic.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = SchemaType{s}
+	return nil
+}
+
+type schemaAlias Schema
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.boolean != nil {
+		return sonic.Marshal(*s.boolean)
+	}
+	// sorted keys keep generated schema files stable
+	return sonic.ConfigStd.Marshal((*schemaAlias)(s))
+}
+
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true", "false":
+		b := string(bytes.TrimSpace(data)) == "true"
+		*s = Schema{boolean: &b}
+		return nil
+	}
+	return sonic.Unmarshal(data, (*schemaAlias)(s))
+}
+
+// ParseSchema parses a json or yaml schema, format is a codec name of pkg/encoding.
+func ParseSchema(data []byte, format string) (*Schema, error) {
+	if format != "json" {
+		codec := encoding.GetCodec(format)
+		if codec == nil {
+			return nil, fmt.Errorf("config: unsupported schema format: %s", format)
+		}
+		var v interface{}
+		if err := codec.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, err = sonic.Marshal(convertMap(v)); err != nil {
+			return nil, err
+		}
+	}
+	s := &Schema{}
+	if err := sonic.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadSchema loads a json or yaml schema file.
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format := strings.TrimPrefix(filepath.Ext(path), ".")
+	if format == "yml" {
+		format = "yaml"
+	}
+	return ParseSchema(data, format)
+}
+
+// SchemaError aggregates every key that failed the schema validation.
+type SchemaError struct {
+	Errors []*FieldError
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return fmt.Sprintf("config: %d schema violation(s): %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *SchemaError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// Validate checks v against the schema and returns a *SchemaError listing
+// every violation. Config values from env and flags are strings, so a
+// string holding a number or a bool is accepted for those types.
+func (s *Schema) Validate(v interface{}) error {
+	var errs []*FieldError
+	s.validate("", v, &errs)
+	if len(errs) > 0 {
+		return &SchemaError{Errors: errs}
+	}
+	return nil
+}
+
+func (s *Schema) validate(path string, v interface{}, errs *[]*FieldError) {
+	fail := func(format string, args ...interface{}) {
+		p := path
+		if p == "" {
+			p = "$"
+		}
+		*errs = append(*errs, &FieldError{Path: p, Err: fmt.Errorf(format, args...)})
+	}
+	if s.boolean != nil {
+		if !*s.boolean {
+			fail("value is not allowed")
+		}
+		return
+	}
+	if len(s.Type) > 0 && !s.Type.matches(v) {
+		fail("expected %s, got %s", strings.Join(s.Type, " or "), typeName(v))
+		return
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if fmt.Sprint(e) == fmt.Sprint(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value %v is not one of %v", v, s.Enum)
+		}
+	}
+	if f, ok := toNumber(v); ok {
+		switch {
+		case s.Minimum != nil && f < *s.Minimum:
+			fail("value %v is less than minimum %v", v, *s.Minimum)
+		case s.Maximum != nil && f > *s.Maximum:
+			fail("value %v is greater than maximum %v", v, *s.Maximum)
+		case s.ExclusiveMinimum != nil && f <= *s.ExclusiveMinimum:
+			fail("value %v is not greater than %v", v, *s.ExclusiveMinimum)
+		case s.ExclusiveMaximum != nil && f >= *s.ExclusiveMaximum:
+			fail("value %v is not less than %v", v, *s.ExclusiveMaximum)
+		}
+	}
+	switch vt := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(vt)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("length %d is less than minLength %d", n, *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("length %d is greater than maxLength %d", n, *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				fail("invalid pattern %q: %v", s.Pattern, err)
+			} else if !re.MatchString(vt) {
+				fail("value %q does not match pattern %q", vt, s.Pattern)
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range s.Required {
+			if _, ok := vt[key]; !ok {
+				*errs = append(*errs, &FieldError{Path: joinPath(path, quoteKey(key)), Err: ErrNotFound})
+			}
+		}
+		keys := make([]string, 0, len(vt))
+		for k := range vt {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sub := joinPath(path, quoteKey(k))
+			if ps, ok := s.Properties[k]; ok {
+				ps.validate(sub, vt[k], errs)
+			} else if s.AdditionalProperties != nil {
+				if b := s.AdditionalProperties.boolean; b != nil && !*b {
+					*errs = append(*errs, &FieldError{Path: sub, Err: fmt.Errorf("unknown key")})
+					continue
+				}
+				s.AdditionalProperties.validate(sub, vt[k], errs)
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(vt) < *s.MinItems {
+			fail("%d items is less than minItems %d", len(vt), *s.MinItems)
+		}
+		if s.MaxItems != nil && len(vt) > *s.MaxItems {
+			fail("%d items is greater than maxItems %d", len(vt), *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range vt {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	}
+}
+
+func (t SchemaType) matches(v interface{}) bool {
+	for _, name := range t {
+		switch name {
+		case "null":
+			if v == nil {
+				return true
+			}
+		case "object":
+			if _, ok := v.(map[string]interface{}); ok {
+				return true
+			}
+		case "array":
+			if _, ok := v.([]interface{}); ok {
+				return true
+			}
+		case "string":
+			if _, ok := v.(string); ok {
+				return true
+			}
+		case "boolean":
+			switch vt := v.(type) {
+			case bool:
+				return true
+			case string:
+				if _, err := strconv.ParseBool(vt); err == nil {
+					return true
+				}
+			}
+		case "number":
+			if _, ok := toNumber(v); ok {
+				return true
+			}
+		case "integer":
+			if f, ok := toNumber(v); ok && f == float64(int64(f)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toNumber converts the numbers of decoded config, and the strings holding one.
+func toNumber(v interface{}) (float64, bool) {
+	switch vt := v.(type) {
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(vt), 64)
+		return f, err == nil
+	case bool, nil, map[string]interface{}, []interface{}:
+		return 0, false
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	if _, ok := toNumber(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// GenerateSchema generates the schema of the struct v, or of the struct v
+// points to, following the tags of Bind: the `config` or `json` tag names
+// the key, `default` sets the default, `validate` maps required, min, max
+// and oneof to their keywords, `description` or `usage` describes the key.
+// Fields without a tag are named by their lower-cased field name. A required
+// field with a default is not listed as required since Bind fills it.
+//
+// Property names are matched case-sensitively as in JSON Schema, while Bind
+// also accepts a key differing in case, so the config keys must use the case
+// of the tags to be validated.
+func GenerateSchema(v interface{}) (*Schema, error) {
+	t := indirectType(reflect.TypeOf(v))
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: schema needs a struct, got %T", v)
+	}
+	g := &schemaGenerator{visiting: make(map[reflect.Type]bool)}
+	s, err := g.generate(t)
+	if err != nil {
+		return nil, err
+	}
+	s.Schema = SchemaDraft
+	return s, nil
+}
+
+type schemaGenerator struct {
+	visiting map[reflect.Type]bool
+}
+
+func (g *schemaGenerator) generate(t reflect.Type) (*Schema, error) {
+	t = indirectType(t)
+	switch {
+	case t == durationType:
+		return &Schema{Type: SchemaType{"string", "integer"}, Format: "duration"}, nil
+	case implementsText(t):
+		return &Schema{Type: SchemaType{"string"}}, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: SchemaType{"boolean"}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: SchemaType{"integer"}}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaType{"number"}}, nil
+	case reflect.String:
+		return &Schema{Type: SchemaType{"string"}}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: SchemaType{"string"}}, nil
+		}
+		items, err := g.generate(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: SchemaType{"array"}, Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("config: schema needs string map keys, got %s", t)
+		}
+		values, err := g.generate(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: SchemaType{"object"}, AdditionalProperties: values}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Struct:
+		if g.visiting[t] {
+			// recursive types are left open
+			return &Schema{Type: SchemaType{"object"}}, nil
+		}
+		g.visiting[t] = true
+		defer delete(g.visiting, t)
+		s := &Schema{Type: SchemaType{"object"}, Properties: make(map[string]*Schema)}
+		if err := g.fields(t, s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("config: schema does not support %s", t)
+}
+
+func (g *schemaGenerator) fields(t reflect.Type, s *Schema) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := FieldName(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			if err := g.fields(indirectType(field.Type), s); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fs, err := g.generate(field.Type)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.Name, err)
+		}
+		fs.Description = field.Tag.Get("description")
+		if fs.Description == "" {
+			fs.Description = field.Tag.Get("usage")
+		}
+		def, hasDefault := field.Tag.Lookup("default")
+		if hasDefault {
+			fs.Default = schemaValue(field.Type, def)
+		}
+		r, err := parseRules(field.Tag.Get("validate"))
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.Name, err)
+		}
+		if r.required && !hasDefault {
+			s.Required = append(s.Required, name)
+		}
+		if err := applyRules(fs, indirectType(field.Type), r); err != nil {
+			return fmt.Errorf("%s: %w", field.Name, err)
+		}
+		s.Properties[name] = fs
+	}
+	return nil
+}
+
+// applyRules maps the min, max and oneof rules of Bind to schema keywords.
+func applyRules(s *Schema, t reflect.Type, r rules) error {
+	for _, bound := range []struct {
+		arg    *string
+		num    **float64
+		length **int
+		items  **int
+	}{
+		{arg: r.min, num: &s.Minimum, length: &s.MinLength, items: &s.MinItems},
+		{arg: r.max, num: &s.Maximum, length: &s.MaxLength, items: &s.MaxItems},
+	} {
+		if bound.arg == nil || t == durationType {
+			continue
+		}
+		switch t.Kind() {
+		case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+			n, err := strconv.Atoi(*bound.arg)
+			if err != nil {
+				return fmt.Errorf("invalid bound %q: %w", *bound.arg, err)
+			}
+			if t.Kind() == reflect.String {
+				*bound.length = &n
+			} else if t.Kind() != reflect.Map {
+				*bound.items = &n
+			}
+		default:
+			f, err := strconv.ParseFloat(*bound.arg, 64)
+			if err != nil {
+				return fmt.Errorf("invalid bound %q: %w", *bound.arg, err)
+			}
+			*bound.num = &f
+		}
+	}
+	for _, o := range r.oneof {
+		s.Enum = append(s.Enum, schemaValue(t, o))
+	}
+	return nil
+}
+
+// schemaValue converts a tag value to the json type of t.
+func schemaValue(t reflect.Type, s string) interface{} {
+	t = indirectType(t)
+	switch {
+	case t == durationType || implementsText(t):
+		return s
+	case t.Kind() == reflect.String:
+		return s
+	case t.Kind() == reflect.Slice:
+		var list []interface{}
+		for _, item := range strings.Split(s, ",") {
+			list = append(list, schemaValue(t.Elem(), strings.TrimSpace(item)))
+		}
+		return list
+	}
+	return convertToType(s)
+}
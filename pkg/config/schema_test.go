@@ -0,0 +1,183 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+type testSchemaServer struct {
+	Addr    string        `config:"addr" default:"0.0.0.0" validate:"required" description:"listen address"`
+	Port    int           `config:"port" validate:"required,min=1,max=65535"`
+	Timeout time.Duration `config:"timeout" default:"1s"`
+	Mode    string        `config:"mode" validate:"oneof=debug release"`
+}
+
+type testSchemaConfig struct {
+	Server  testSchemaServer             `config:"server"`
+	Hosts   []string                     `config:"hosts" validate:"min=1"`
+	Limits  map[string]float64           `config:"limits"`
+	Enabled bool                         `json:"enabled"`
+	Name    string                       `validate:"max=8"`
+	Extra   map[string]*testSchemaServer `config:"extra"`
+	Skipped string                       `config:"-"`
+}
+
+func TestGenerateSchema(t *testing.T) {
+	s, err := GenerateSchema(&testSchemaConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := sonic.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := sonic.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	server := got["properties"].(map[string]interface{})["server"].(map[string]interface{})
+	port := server["properties"].(map[string]interface{})["port"]
+	if want := map[string]interface{}{"type": "integer", "minimum": float64(1), "maximum": float64(65535)}; !reflect.DeepEqual(port, want) {
+		t.Errorf("port schema = %v, want %v", port, want)
+	}
+	if !reflect.DeepEqual(server["required"], []interface{}{"port"}) {
+		t.Errorf("server required = %v", server["required"])
+	}
+	addr := server["properties"].(map[string]interface{})["addr"].(map[string]interface{})
+	if addr["default"] != "0.0.0.0" || addr["description"] != "listen address" {
+		t.Errorf("addr schema = %v", addr)
+	}
+	props := got["properties"].(map[string]interface{})
+	if _, ok := props["Skipped"]; ok {
+		t.Error("skipped field in schema")
+	}
+	if _, ok := props["name"]; !ok {
+		t.Error("untagged field should be named name")
+	}
+	if got["$schema"] != SchemaDraft {
+		t.Errorf("$schema = %v", got["$schema"])
+	}
+
+	// the generated schema parses back
+	parsed, err := ParseSchema(data, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, s) {
+		t.Errorf("ParseSchema(Marshal(s)) = %+v, want %+v", parsed, s)
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	s, err := GenerateSchema(testSchemaConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := map[string]interface{}{
+		"server": map[string]interface{}{"port": "8080", "timeout": "2s", "mode": "debug"},
+		"hosts":  []interface{}{"a"},
+		"limits": map[string]interface{}{"qps": float64(10)},
+		"other":  "keys of other components are allowed",
+	}
+	if err := s.Validate(valid); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	invalid := map[string]interface{}{
+		"server":  map[string]interface{}{"port": float64(70000), "mode": "test"},
+		"hosts":   []interface{}{},
+		"limits":  map[string]interface{}{"qps": "many"},
+		"enabled": "maybe",
+		"name":    "too long a name",
+	}
+	err = s.Validate(invalid)
+	var se *SchemaError
+	if !errors.As(err, &se) {
+		t.Fatalf("want *SchemaError, got: %v", err)
+	}
+	var paths []string
+	for _, fe := range se.Errors {
+		paths = append(paths, fe.Path)
+	}
+	want := []string{"enabled", "hosts", "limits.qps", "name", "server.mode", "server.port"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("invalid paths = %v, want %v (%v)", paths, want, err)
+	}
+	if err := s.Validate(map[string]interface{}{"server": map[string]interface{}{}}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want ErrNotFound for the required port, got: %v", err)
+	}
+}
+
+func TestLoadSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.yaml")
+	if err := os.WriteFile(path, []byte(`
+type: object
+required: [name]
+additionalProperties: false
+properties:
+  name:
+    type: string
+    pattern: "^[a-z]+$"
+  tags:
+    type: array
+    items: {type: string}
+`), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadSchema(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate(map[string]interface{}{"name": "petal", "tags": []interface{}{"a"}}); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	err = s.Validate(map[string]interface{}{"name": "Petal", "tags": []interface{}{1}, "other": 1})
+	for _, want := range []string{"name: value \"Petal\" does not match", "other: unknown key", "tags[0]: expected string"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("want error containing %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestSchemaRejectsReload(t *testing.T) {
+	s, err := GenerateSchema(testSchemaConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := newTestUpdateSource(`{"server":{"port":8080}}`)
+	c := New(WithSource(source), WithSchema(s))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	changes := make(chan ChangeSet, 2)
+	c.Subscribe("", func(cs ChangeSet) { changes <- cs })
+
+	source.updates <- `{"server":{"port":0}}`
+	source.updates <- `{"server":{"port":9090}}`
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload")
+	}
+	if got, _ := c.Value("server.port").Int(); got != 9090 {
+		t.Errorf("server.port = %d, want 9090", got)
+	}
+	select {
+	case cs := <-changes:
+		t.Errorf("unexpected change: %v", cs)
+	default:
+	}
+
+	bad := New(WithSource(&testStaticSource{kvs: []*KeyValue{{Key: "a.json", Format: "json", Value: []byte(`{"server":{}}`)}}}), WithSchema(s))
+	defer bad.Close()
+	if err := bad.Load(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want ErrNotFound, got: %v", err)
+	}
+}
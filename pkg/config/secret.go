@@ -0,0 +1,311 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the resolved secrets in dumps of the config.
+const Redacted = "******"
+
+// referenceRegexp matches ${@scheme:ref}, the scheme must be registered by a
+// SecretProvider. The @ keeps the references apart from the placeholders
+// ${key:default}, a config key env with a default is still ${env:dev}.
+var referenceRegexp = regexp.MustCompile(`\${@([a-zA-Z][a-zA-Z0-9+.-]*):([^}]*)}`)
+
+// SecretProvider resolves the references of its scheme, ${@scheme:ref}.
+// The values it resolves are redacted from Source and Explain.
+type SecretProvider interface {
+	// Scheme returns the scheme of the references resolved by the provider.
+	Scheme() string
+	// Resolve returns the secret ref refers to.
+	Resolve(ref string) (string, error)
+}
+
+// WithSecretProvider registers secret providers, a provider replaces the
+// provider of the same scheme, including the builtin env, file and base64 ones.
+func WithSecretProvider(p ...SecretProvider) Option {
+	return func(o *options) {
+		for _, provider := range p {
+			o.secrets.providers[provider.Scheme()] = provider
+		}
+	}
+}
+
+// secrets resolves references through the providers and decrypts the ENC
+// values with the key ring.
+type secrets struct {
+	providers map[string]SecretProvider
+	keys      *KeyRing
+	// redactEncrypted replaces the ENC values by Redacted without keys.
+	redactEncrypted bool
+}
+
+func newSecrets() *secrets {
+	return &secrets{providers: map[string]SecretProvider{
+		"env":    envProvider{},
+		"file":   fileProvider{},
+		"base64": base64Provider{},
+	}}
+}
+
+// secretPaths is the set of the paths of the leaves holding resolved
+// secrets, only these leaves are redacted.
+type secretPaths map[string]struct{}
+
+// minRedactLen is the length of the shortest secret redacted from the
+// documents logged with errors, shorter ones such as 1 or true would hide
+// unrelated text.
+const minRedactLen = 6
+
+// resolvedSecrets is what a resolve found, the paths of the leaves holding
+// secrets and the secrets, longest first so a secret containing another
+// one is redacted whole. It is replaced by each successful resolve.
+type resolvedSecrets struct {
+	paths  secretPaths
+	values []string
+}
+
+// resolve replaces the references and ENC values of the string leaves of
+// values. It returns the secrets found, and the paths of the leaves holding
+// placeholders that may copy secrets once resolved. literal escapes the
+// secrets for the placeholder pass following, see expand.
+func (s *secrets) resolve(values map[string]interface{}, literal bool) (found *resolvedSecrets, placeholders secretPaths, err error) {
+	found, placeholders = &resolvedSecrets{paths: make(secretPaths)}, make(secretPaths)
+	var walk func(path string, v interface{}) error
+	leaf := func(path, str string) (string, error) {
+		resolved, ok, err := s.expand(str, literal, found)
+		if err != nil {
+			return "", fmt.Errorf("config: resolve %s: %w", path, err)
+		}
+		if ok {
+			found.paths[path] = struct{}{}
+		} else if strings.Contains(str, "${") {
+			placeholders[path] = struct{}{}
+		}
+		return resolved, nil
+	}
+	walk = func(path string, v interface{}) error {
+		switch vt := v.(type) {
+		case map[string]interface{}:
+			for k, sub := range vt {
+				p := joinPath(path, quoteKey(k))
+				if str, ok := sub.(string); ok {
+					resolved, err := leaf(p, str)
+					if err != nil {
+						return err
+					}
+					vt[k] = resolved
+					continue
+				}
+				if err := walk(p, sub); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			for i, sub := range vt {
+				p := fmt.Sprintf("%s[%d]", path, i)
+				if str, ok := sub.(string); ok {
+					resolved, err := leaf(p, str)
+					if err != nil {
+						return err
+					}
+					vt[i] = resolved
+					continue
+				}
+				if err := walk(p, sub); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk("", values); err != nil {
+		return nil, nil, err
+	}
+	return found, placeholders, nil
+}
+
+// expand decrypts the ENC values and replaces the references of registered
+// schemes in str, ok reports whether a secret was resolved and adds it to
+// found. The secrets are not scanned again, and when literal is set their
+// ${ are escaped as $${ so the placeholder pass keeps them as they are.
+func (s *secrets) expand(str string, literal bool, found *resolvedSecrets) (_ string, ok bool, err error) {
+	type span struct {
+		start, end int
+		secret     string
+	}
+	var spans []span
+	for _, m := range encRegexp.FindAllStringSubmatchIndex(str, -1) {
+		if s.keys == nil {
+			if !s.redactEncrypted {
+				return "", false, ErrNoKeyRing
+			}
+			spans = append(spans, span{m[0], m[1], Redacted})
+			continue
+		}
+		plain, err := s.keys.decrypt(str[m[2]:m[3]], str[m[4]:m[5]], str[m[6]:m[7]])
+		if err != nil {
+			return "", false, err
+		}
+		spans = append(spans, span{m[0], m[1], plain})
+	}
+	for _, m := range referenceRegexp.FindAllStringSubmatchIndex(str, -1) {
+		p, ok := s.providers[str[m[2]:m[3]]]
+		// $${@scheme:ref} is an escaped reference, see resolvePlaceholders
+		if !ok || (m[0] > 0 && str[m[0]-1] == '$') {
+			continue
+		}
+		secret, err := p.Resolve(str[m[4]:m[5]])
+		if err != nil {
+			return "", false, fmt.Errorf("%s: %w", str[m[2]:m[3]], err)
+		}
+		spans = append(spans, span{m[0], m[1], secret})
+	}
+	if len(spans) == 0 {
+		return str, false, nil
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var b strings.Builder
+	last := 0
+	for _, sp := range spans {
+		if sp.start < last {
+			continue
+		}
+		found.add(sp.secret)
+		b.WriteString(str[last:sp.start])
+		if literal {
+			b.WriteString(escapePlaceholders(sp.secret, strings.HasPrefix(str[sp.end:], "{")))
+		} else {
+			b.WriteString(sp.secret)
+		}
+		last = sp.end
+	}
+	b.WriteString(str[last:])
+	return b.String(), true, nil
+}
+
+// escapePlaceholders escapes the ${ of secret as $${, brace reports that
+// secret is followed by a {, so a trailing $ would start a placeholder.
+func escapePlaceholders(secret string, brace bool) string {
+	secret = strings.ReplaceAll(secret, "${", "$${")
+	if brace && strings.HasSuffix(secret, "$") {
+		secret += "$"
+	}
+	return secret
+}
+
+// add adds secret to the values, longest first.
+func (r *resolvedSecrets) add(secret string) {
+	if secret == "" || secret == Redacted || slices.Contains(r.values, secret) {
+		return
+	}
+	i := sort.Search(len(r.values), func(i int) bool { return len(r.values[i]) < len(secret) })
+	r.values = slices.Insert(r.values, i, secret)
+}
+
+// redactString replaces the secrets found in str, it is only used for the
+// leaves holding secrets.
+func (r *resolvedSecrets) redactString(str string) string {
+	if r == nil {
+		return str
+	}
+	for _, v := range r.values {
+		str = strings.ReplaceAll(str, v, Redacted)
+	}
+	return str
+}
+
+// redactDocument replaces the secrets of minRedactLen or more found in a
+// document logged with an error.
+func (r *resolvedSecrets) redactDocument(doc string) string {
+	if r == nil {
+		return doc
+	}
+	for _, v := range r.values {
+		if len(v) >= minRedactLen {
+			doc = strings.ReplaceAll(doc, v, Redacted)
+		}
+	}
+	return doc
+}
+
+// contains reports whether str holds a secret.
+func (r *resolvedSecrets) contains(str string) bool {
+	return str != "" && r.redactString(str) != str
+}
+
+// redact returns a copy of v with the secrets of the leaves at r.paths
+// replaced, path is the path of v.
+func (r *resolvedSecrets) redact(path string, v interface{}) interface{} {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		dst := make(map[string]interface{}, len(vt))
+		for k, sub := range vt {
+			dst[k] = r.redact(joinPath(path, quoteKey(k)), sub)
+		}
+		return dst
+	case []interface{}:
+		dst := make([]interface{}, len(vt))
+		for i, sub := range vt {
+			dst[i] = r.redact(fmt.Sprintf("%s[%d]", path, i), sub)
+		}
+		return dst
+	}
+	if r == nil {
+		return v
+	}
+	if _, ok := r.paths[path]; !ok {
+		return v
+	}
+	if str, ok := v.(string); ok {
+		return r.redactString(str)
+	}
+	// a secret converted by a transform, such as ${db.port|int}
+	return Redacted
+}
+
+// envProvider resolves ${@env:NAME} from the environment.
+type envProvider struct{}
+
+func (envProvider) Scheme() string { return "env" }
+
+func (envProvider) Resolve(ref string) (string, error) {
+	v, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref)
+	}
+	return v, nil
+}
+
+// fileProvider resolves ${@file:/run/secrets/db} from the content of the file,
+// without the trailing newline.
+type fileProvider struct{}
+
+func (fileProvider) Scheme() string { return "file" }
+
+func (fileProvider) Resolve(ref string) (string, error) {
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// base64Provider resolves ${@base64:cGV0YWw=} by decoding the reference.
+type base64Provider struct{}
+
+func (base64Provider) Scheme() string { return "base64" }
+
+func (base64Provider) Resolve(ref string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(ref)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,119 @@
+// Package secret provides config.SecretProvider implementations.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/banbridge/common/pkg/config"
+)
+
+// KeySize is the size of the AES-256 keys sealing secret files.
+const KeySize = 32
+
+var (
+	// ErrKeySize is returned for keys that are not KeySize bytes long.
+	ErrKeySize = errors.New("secret: key must be 32 bytes")
+	// ErrNotFound is returned when the secret file has no secret of the name.
+	ErrNotFound = errors.New("secret: not found")
+)
+
+var _ config.SecretProvider = (*fileProvider)(nil)
+
+// fileProvider resolves ${@secret:name} from a local file holding a json
+// object of secrets sealed with AES-256-GCM.
+type fileProvider struct {
+	path string
+	key  []byte
+}
+
+// NewFileProvider new a provider of the secrets sealed in the file path
+// with key, see WriteFile. The file is read at every resolve so a rotated
+// file is picked up by the next reload of the config.
+func NewFileProvider(path string, key []byte) config.SecretProvider {
+	return &fileProvider{path: path, key: key}
+}
+
+func (p *fileProvider) Scheme() string {
+	return "secret"
+}
+
+func (p *fileProvider) Resolve(name string) (string, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return "", err
+	}
+	secrets, err := Open(p.key, data)
+	if err != nil {
+		return "", err
+	}
+	v, ok := secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return v, nil
+}
+
+// WriteFile seals secrets with key into the file path.
+func WriteFile(path string, key []byte, secrets map[string]string) error {
+	data, err := Seal(key, secrets)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Seal encrypts secrets with key, the nonce is prepended to the result.
+func Seal(key []byte, secrets map[string]string) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := sonic.Marshal(secrets)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+// Open decrypts the secrets sealed by Seal.
+func Open(key []byte, data []byte) (map[string]string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("secret: sealed data too short")
+	}
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make(map[string]string)
+	if err := sonic.Unmarshal(plain, &secrets); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrKeySize
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,70 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/banbridge/common/pkg/config"
+)
+
+func TestFileProvider(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, KeySize)
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	if err := WriteFile(path, key, map[string]string{"db/password": "s3cr3t"}); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewFileProvider(path, key)
+	if got, err := p.Resolve("db/password"); err != nil || got != "s3cr3t" {
+		t.Errorf("Resolve() = %q, %v", got, err)
+	}
+	if _, err := p.Resolve("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want ErrNotFound, got: %v", err)
+	}
+	if _, err := NewFileProvider(path, bytes.Repeat([]byte{8}, KeySize)).Resolve("db/password"); err == nil {
+		t.Error("want error for a wrong key")
+	}
+	if _, err := NewFileProvider(path, key[:16]).Resolve("db/password"); !errors.Is(err, ErrKeySize) {
+		t.Errorf("want ErrKeySize, got: %v", err)
+	}
+
+	c := config.New(
+		config.WithSource(&staticSource{data: `{"db":{"dsn":"root:${@secret:db/password}@tcp(db)"}}`}),
+		config.WithSecretProvider(p),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if got, _ := c.Value("db.dsn").String(); got != "root:s3cr3t@tcp(db)" {
+		t.Errorf("db.dsn = %q", got)
+	}
+}
+
+type staticSource struct {
+	data string
+}
+
+func (s *staticSource) Load() ([]*config.KeyValue, error) {
+	return []*config.KeyValue{{Key: "static", Value: []byte(s.data), Format: "json"}}, nil
+}
+
+func (s *staticSource) Watch() (config.Watcher, error) {
+	return &blockingWatcher{exit: make(chan struct{})}, nil
+}
+
+type blockingWatcher struct {
+	exit chan struct{}
+}
+
+func (w *blockingWatcher) Next() ([]*config.KeyValue, error) {
+	<-w.exit
+	return nil, errors.New("stopped")
+}
+
+func (w *blockingWatcher) Stop() error {
+	close(w.exit)
+	return nil
+}
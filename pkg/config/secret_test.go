@@ -0,0 +1,239 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testSecretProvider map[string]string
+
+func (p testSecretProvider) Scheme() string { return "vault" }
+
+func (p testSecretProvider) Resolve(ref string) (string, error) {
+	if v, ok := p[ref]; ok {
+		return v, nil
+	}
+	return "", errors.New("no such secret")
+}
+
+func TestSecretReferences(t *testing.T) {
+	t.Setenv("SECRET_TEST_DB_PASSWORD", "env-pass")
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := New(
+		WithSource(&testStaticSource{kvs: []*KeyValue{{
+			Key:    "app.json",
+			Format: "json",
+			Value: []byte(`{
+				"db": {"user": "root", "password": "${@env:SECRET_TEST_DB_PASSWORD}", "dsn": "${db.user}:${db.password}@db"},
+				"token": "${@file:` + path + `}",
+				"name": "${@base64:cGV0YWw=}",
+				"api": {"keys": ["${@vault:api}"]},
+				"port": "${server.port:8080}",
+				"literal": "$${@env:SECRET_TEST_DB_PASSWORD}"
+			}`),
+		}}}),
+		WithSecretProvider(testSecretProvider{"api": "vault-key"}),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for key, want := range map[string]string{
+		"db.password": "env-pass",
+		"db.dsn":      "root:env-pass@db",
+		"token":       "file-token",
+		"name":        "petal",
+		"port":        "8080",
+		"literal":     "${@env:SECRET_TEST_DB_PASSWORD}",
+	} {
+		if got, err := c.Value(key).String(); err != nil || got != want {
+			t.Errorf("%s want: %s, got: %s (%v)", key, want, got, err)
+		}
+	}
+
+	var scanned struct {
+		API struct {
+			Keys []string `json:"keys"`
+		} `json:"api"`
+	}
+	if err := c.Scan(&scanned); err != nil {
+		t.Fatal(err)
+	}
+	if len(scanned.API.Keys) != 1 || scanned.API.Keys[0] != "vault-key" {
+		t.Errorf("Scan() should not redact secrets, got: %v", scanned.API.Keys)
+	}
+
+	data, err := c.(*config).reader.Source()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"env-pass", "file-token", "vault-key", "petal"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Source() leaks %s: %s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), `"root:`+Redacted+`@db"`) {
+		t.Errorf("Source() should redact secrets inside values: %s", data)
+	}
+
+	e, err := c.Explain("db.password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Value != Redacted {
+		t.Errorf("Explain() value want: %s, got: %v", Redacted, e.Value)
+	}
+}
+
+func TestSecretSchemesAreNotKeys(t *testing.T) {
+	c := New(WithSource(&testStaticSource{kvs: []*KeyValue{{
+		Key:    "app.json",
+		Format: "json",
+		Value:  []byte(`{"file": "app.yaml", "name": "${env:dev}", "path": "${file:none}", "data": "${base64:x}"}`),
+	}}}))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for key, want := range map[string]string{
+		"name": "dev",
+		"path": "app.yaml",
+		"data": "x",
+	} {
+		if got, err := c.Value(key).String(); err != nil || got != want {
+			t.Errorf("%s want: %s, got: %s (%v)", key, want, got, err)
+		}
+	}
+}
+
+func TestSecretRedactionByPath(t *testing.T) {
+	c := New(
+		WithSource(&testStaticSource{kvs: []*KeyValue{{
+			Key:    "app.json",
+			Format: "json",
+			Value: []byte(`{
+				"port": "${@vault:port}",
+				"addr": "h:${port}",
+				"typed": "${port|int}",
+				"url": "http://h:8080",
+				"list": ["8080", "${@vault:port}"]
+			}`),
+		}}}),
+		WithSecretProvider(testSecretProvider{"port": "80"}),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	data, err := Dump(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"port":"` + Redacted + `"`,
+		`"addr":"h:` + Redacted + `"`,
+		`"typed":"` + Redacted + `"`,
+		`"url":"http://h:8080"`,
+		`"list":["8080","` + Redacted + `"]`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Dump() misses %s: %s", want, data)
+		}
+	}
+}
+
+func TestSecretLiteral(t *testing.T) {
+	k, err := ParseKeyRing([]byte("k1:" + testKey(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := k.Encrypt("e${x}$${y}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(
+		WithSource(&testStaticSource{kvs: []*KeyValue{{
+			Key:    "app.json",
+			Format: "json",
+			Value: []byte(`{
+				"x": "expanded",
+				"password": "${@vault:pass}",
+				"prefix": "${@vault:dollar}{x}",
+				"enc": "` + enc + `",
+				"copy": "<${password}>"
+			}`),
+		}}}),
+		WithKeyRing(k),
+		WithSecretProvider(testSecretProvider{"pass": "p${x}$${y}", "dollar": "p$"}),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for key, want := range map[string]string{
+		"password": "p${x}$${y}",
+		"prefix":   "p${x}",
+		"enc":      "e${x}$${y}",
+		"copy":     "<p${x}$${y}>",
+	} {
+		if got, err := c.Value(key).String(); err != nil || got != want {
+			t.Errorf("%s want: %s, got: %s (%v)", key, want, got, err)
+		}
+	}
+}
+
+func TestSecretReferenceError(t *testing.T) {
+	c := New(WithSource(&testStaticSource{kvs: []*KeyValue{{
+		Key:    "app.json",
+		Format: "json",
+		Value:  []byte(`{"password": "${@env:SECRET_TEST_NOT_SET}"}`),
+	}}}))
+	defer c.Close()
+	err := c.Load()
+	if err == nil || !strings.Contains(err.Error(), "SECRET_TEST_NOT_SET") {
+		t.Errorf("want error for unset variable, got: %v", err)
+	}
+}
+
+func TestSecretRotation(t *testing.T) {
+	src := newTestUpdateSource(`{"password": "${@vault:a}"}`)
+	c := New(WithSource(src), WithSecretProvider(testSecretProvider{"a": "old-secret", "b": "new-secret"}))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	src.updates <- `{"password": "${@vault:b}"}`
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if v, _ := c.Value("password").String(); v == "new-secret" {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	r := c.(*config).reader.(*reader)
+	r.lock.Lock()
+	values := r.secrets.values
+	r.lock.Unlock()
+	if !reflect.DeepEqual(values, []string{"new-secret"}) {
+		t.Errorf("secrets want: [new-secret], got: %v", values)
+	}
+}
+
+func TestSecretRedactDocument(t *testing.T) {
+	r := &resolvedSecrets{}
+	for _, v := range []string{"1", "true", "long-secret"} {
+		r.add(v)
+	}
+	got := r.redactDocument(`{"retries": 1, "debug": true, "password": "long-secret"}`)
+	if want := `{"retries": 1, "debug": true, "password": "` + Redacted + `"}`; got != want {
+		t.Errorf("want: %s, got: %s", want, got)
+	}
+}
@@ -5,6 +5,14 @@ type KeyValue struct {
 	Key    string
 	Value  []byte
 	Format string
+	// Path is the file Value was read from, the files included by Value
+	// are resolved relative to it. It is empty for values not read from a file.
+	Path string
+	// Namespace is the keys the decoded Value is nested under, a Value
+	// without Format is the value of the last key.
+	Namespace []string
+	// Deleted reports that Key was removed from the source, Value is ignored.
+	Deleted bool
 }
 
 // Source is config source.
@@ -13,8 +21,17 @@ type Source interface {
 	Watch() (Watcher, error)
 }
 
-// Watcher watches a source for changes.
+// Watcher watches a source for changes. The KeyValues returned by Next are
+// merged over the config, a KeyValue with Deleted removes its Key. For the
+// sources of WithLayer a KeyValue replaces the document of the same Key.
 type Watcher interface {
 	Next() ([]*KeyValue, error)
 	Stop() error
 }
+
+// Committer is implemented by the watchers that need to know when the
+// KeyValues returned by Next were merged into the config, Commit is not
+// called for the KeyValues of a failed reload.
+type Committer interface {
+	Commit(kvs []*KeyValue)
+}
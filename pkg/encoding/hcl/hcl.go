@@ -0,0 +1,165 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+
+	"github.com/banbridge/common/pkg/encoding"
+	"github.com/banbridge/common/pkg/encoding/internal/tree"
+)
+
+// Name is the name registered for the hcl codec.
+const Name = "hcl"
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// codec is a Codec implementation with HCL 1. Blocks decode to nested maps,
+// labels included, so service "web" { port = 80 } decodes to
+// map[service]map[web]map[port]80, and repeated blocks are merged.
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	m, err := tree.ToMap(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeObject(&buf, m, 0)
+	return buf.Bytes(), nil
+}
+
+var identRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-.]*$`)
+
+func writeObject(buf *bytes.Buffer, m map[string]interface{}, depth int) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	indent := strings.Repeat("  ", depth)
+	for _, k := range keys {
+		key := k
+		if !identRegexp.MatchString(k) {
+			key = strconv.Quote(k)
+		}
+		if sub, ok := m[k].(map[string]interface{}); ok {
+			fmt.Fprintf(buf, "%s%s {\n", indent, key)
+			writeObject(buf, sub, depth+1)
+			fmt.Fprintf(buf, "%s}\n", indent)
+			continue
+		}
+		fmt.Fprintf(buf, "%s%s = ", indent, key)
+		writeValue(buf, m[k], depth)
+		buf.WriteByte('\n')
+	}
+}
+
+func writeValue(buf *bytes.Buffer, v interface{}, depth int) {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		buf.WriteString("{\n")
+		writeObject(buf, vt, depth+1)
+		buf.WriteString(strings.Repeat("  ", depth) + "}")
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, sub := range vt {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			writeValue(buf, sub, depth)
+		}
+		buf.WriteByte(']')
+	case string:
+		buf.WriteString(strconv.Quote(vt))
+	case nil:
+		buf.WriteString(`""`)
+	default:
+		fmt.Fprint(buf, vt)
+	}
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	file, err := hcl.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	list, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return fmt.Errorf("hcl: unexpected root %T", file.Node)
+	}
+	m, err := decodeObject(list)
+	if err != nil {
+		return err
+	}
+	return tree.Assign(m, v)
+}
+
+// decodeObject decodes the items of list, the keys of an item after the
+// first one are the labels of a block and are nested.
+func decodeObject(list *ast.ObjectList) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	for _, item := range list.Items {
+		value, err := decodeValue(item.Val)
+		if err != nil {
+			return nil, err
+		}
+		path := make([]string, len(item.Keys))
+		for i, k := range item.Keys {
+			path[i] = fmt.Sprint(k.Token.Value())
+		}
+		for i := len(path) - 1; i > 0; i-- {
+			value = map[string]interface{}{path[i]: value}
+		}
+		m[path[0]] = merge(m[path[0]], value)
+	}
+	return m, nil
+}
+
+func decodeValue(node ast.Node) (interface{}, error) {
+	switch n := node.(type) {
+	case *ast.ObjectType:
+		return decodeObject(n.List)
+	case *ast.ListType:
+		list := make([]interface{}, 0, len(n.List))
+		for _, sub := range n.List {
+			v, err := decodeValue(sub)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case *ast.LiteralType:
+		return n.Token.Value(), nil
+	}
+	return nil, fmt.Errorf("hcl: unexpected node %T at %s", node, node.Pos())
+}
+
+// merge merges the maps of repeated blocks, other values are replaced.
+func merge(dst, src interface{}) interface{} {
+	dm, ok := dst.(map[string]interface{})
+	if !ok {
+		return src
+	}
+	sm, ok := src.(map[string]interface{})
+	if !ok {
+		return src
+	}
+	for k, v := range sm {
+		dm[k] = merge(dm[k], v)
+	}
+	return dm
+}
+
+func (codec) Name() string {
+	return Name
+}
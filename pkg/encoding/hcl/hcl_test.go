@@ -0,0 +1,74 @@
+package hcl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+	doc := `
+name = "petal"
+port = 8080
+ratio = 0.5
+debug = true
+tags = ["a", "b"]
+
+server {
+  host = "localhost"
+}
+
+server {
+  port = 80
+}
+
+service "web" {
+  replicas = 2
+}
+`
+	got := make(map[string]interface{})
+	if err := (codec{}).Unmarshal([]byte(doc), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"name":    "petal",
+		"port":    int64(8080),
+		"ratio":   0.5,
+		"debug":   true,
+		"tags":    []interface{}{"a", "b"},
+		"server":  map[string]interface{}{"host": "localhost", "port": int64(80)},
+		"service": map[string]interface{}{"web": map[string]interface{}{"replicas": int64(2)}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Unmarshal() = %v, want %v", got, want)
+	}
+
+	if err := (codec{}).Unmarshal([]byte("a = {"), &got); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := map[string]interface{}{
+		"name":     "petal ${env}",
+		"port":     int64(8080),
+		"enabled":  false,
+		"a-b.c":    "dotted",
+		"with key": "quoted",
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a"},
+			"b",
+		},
+		"db": map[string]interface{}{"primary": map[string]interface{}{"host": "db"}},
+	}
+	data, err := (codec{}).Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := (codec{}).Unmarshal(data, &out); err != nil {
+		t.Fatalf("%v, encoded:\n%s", err, data)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip changed %v into %v, encoded:\n%s", in, out, data)
+	}
+}
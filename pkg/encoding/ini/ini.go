@@ -0,0 +1,160 @@
+package ini
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/banbridge/common/pkg/encoding"
+	"github.com/banbridge/common/pkg/encoding/internal/tree"
+)
+
+// Name is the name registered for the ini codec.
+const Name = "ini"
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// codec is a Codec implementation with ini files. Sections are nested at
+// dots, [server.http] decodes to map[server]map[http], the keys before the
+// first section are top level keys and values are strings. Lists cannot be
+// encoded.
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	m, err := tree.ToMap(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeSection(&buf, "", m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeSection writes the values of m under the header of name, then the
+// maps of m as sub sections.
+func writeSection(buf *bytes.Buffer, name string, m map[string]interface{}) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sections []string
+	header := name != ""
+	for _, k := range keys {
+		switch vt := m[k].(type) {
+		case map[string]interface{}:
+			sections = append(sections, k)
+			continue
+		case []interface{}:
+			return fmt.Errorf("ini: %s: lists are not supported", joinName(name, k))
+		case nil:
+			writeHeader(buf, name, &header)
+			fmt.Fprintf(buf, "%s =\n", k)
+		default:
+			writeHeader(buf, name, &header)
+			fmt.Fprintf(buf, "%s = %s\n", k, quote(fmt.Sprint(vt)))
+		}
+	}
+	// an empty section keeps its header
+	if len(m) == 0 {
+		writeHeader(buf, name, &header)
+	}
+	for _, k := range sections {
+		if err := writeSection(buf, joinName(name, k), m[k].(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeHeader writes the header of the section once.
+func writeHeader(buf *bytes.Buffer, name string, pending *bool) {
+	if !*pending {
+		return
+	}
+	*pending = false
+	if buf.Len() > 0 {
+		buf.WriteByte('\n')
+	}
+	fmt.Fprintf(buf, "[%s]\n", name)
+}
+
+func joinName(name, key string) string {
+	if name == "" {
+		return key
+	}
+	return name + "." + key
+}
+
+// quote quotes the values that would not read back the same.
+func quote(s string) string {
+	if s != strings.TrimSpace(s) || strings.ContainsAny(s, "\n\r\"';#") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	m := make(map[string]interface{})
+	section := m
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("ini: line %d: unclosed section %s", lineNo, line)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return fmt.Errorf("ini: line %d: empty section name", lineNo)
+			}
+			var err error
+			if section, err = tree.Map(m, strings.Split(name, ".")); err != nil {
+				return fmt.Errorf("ini: line %d: %w", lineNo, err)
+			}
+			continue
+		}
+		i := strings.IndexAny(line, "=:")
+		if i <= 0 {
+			return fmt.Errorf("ini: line %d: expected key = value", lineNo)
+		}
+		value, err := unquote(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			return fmt.Errorf("ini: line %d: %w", lineNo, err)
+		}
+		if err := tree.Set(section, []string{strings.TrimSpace(line[:i])}, value); err != nil {
+			return fmt.Errorf("ini: line %d: %w", lineNo, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return tree.Assign(m, v)
+}
+
+func unquote(s string) (string, error) {
+	if len(s) < 2 {
+		return s, nil
+	}
+	switch {
+	case s[0] == '"' && s[len(s)-1] == '"':
+		return strconv.Unquote(s)
+	case s[0] == '\'' && s[len(s)-1] == '\'':
+		return s[1 : len(s)-1], nil
+	}
+	return s, nil
+}
+
+func (codec) Name() string {
+	return Name
+}
@@ -0,0 +1,71 @@
+package ini
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+	doc := `; comment
+name = petal
+
+[server]
+host = localhost
+port: 8080
+
+# comment
+[server.tls]
+cert = "/etc/cert.pem"
+note = ' keep spaces '
+
+[db]
+`
+	got := make(map[string]interface{})
+	if err := (codec{}).Unmarshal([]byte(doc), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"name": "petal",
+		"server": map[string]interface{}{
+			"host": "localhost",
+			"port": "8080",
+			"tls":  map[string]interface{}{"cert": "/etc/cert.pem", "note": " keep spaces "},
+		},
+		"db": map[string]interface{}{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Unmarshal() = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"[server", "novalue", "a = 1\n[a]"} {
+		if err := (codec{}).Unmarshal([]byte(bad), &got); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := map[string]interface{}{
+		"name": "petal",
+		"server": map[string]interface{}{
+			"host": "localhost",
+			"tls":  map[string]interface{}{"cert": "a;b", "note": " spaces "},
+		},
+		"empty": map[string]interface{}{},
+	}
+	data, err := (codec{}).Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := (codec{}).Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip changed %v into %v, encoded:\n%s", in, out, data)
+	}
+
+	if _, err := (codec{}).Marshal(map[string]interface{}{"list": []interface{}{"a"}}); err == nil {
+		t.Fatal("expected an error for a list")
+	}
+}
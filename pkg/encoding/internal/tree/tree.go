@@ -0,0 +1,84 @@
+// Package tree converts between Go values and the map[string]interface{}
+// trees decoded by the codecs of formats without a native struct mapping.
+package tree
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+// Assign stores the decoded tree m into v. A map target is filled in place,
+// so a map passed by value is updated too, other targets are decoded from
+// the json of m.
+func Assign(m map[string]interface{}, v interface{}) error {
+	if target, ok := v.(*map[string]interface{}); ok {
+		if *target == nil {
+			*target = make(map[string]interface{}, len(m))
+		}
+		for k, sub := range m {
+			(*target)[k] = sub
+		}
+		return nil
+	}
+	data, err := sonic.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return sonic.Unmarshal(data, v)
+}
+
+// ToMap returns v as a tree, structs are converted through json.
+func ToMap(v interface{}) (map[string]interface{}, error) {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		return vt, nil
+	case *map[string]interface{}:
+		return *vt, nil
+	}
+	data, err := sonic.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := sonic.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("encoding: %T is not an object: %w", v, err)
+	}
+	return m, nil
+}
+
+// Map returns the map at the nested keys of path in m, creating the maps
+// on the way. It fails when a key on the way holds a value that is not a map.
+func Map(m map[string]interface{}, path []string) (map[string]interface{}, error) {
+	for i, k := range path {
+		next, ok := m[k]
+		if !ok {
+			sub := make(map[string]interface{})
+			m[k] = sub
+			m = sub
+			continue
+		}
+		sub, ok := next.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("key %s is both a value and a map", strings.Join(path[:i+1], "."))
+		}
+		m = sub
+	}
+	return m, nil
+}
+
+// Set stores value at the nested keys of path in m like Map, it also fails
+// when the last key already holds a map.
+func Set(m map[string]interface{}, path []string, value interface{}) error {
+	parent, err := Map(m, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+	if _, ok := parent[last].(map[string]interface{}); ok {
+		return fmt.Errorf("key %s is both a value and a map", strings.Join(path, "."))
+	}
+	parent[last] = value
+	return nil
+}
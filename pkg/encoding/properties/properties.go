@@ -0,0 +1,278 @@
+package properties
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/banbridge/common/pkg/encoding"
+	"github.com/banbridge/common/pkg/encoding/internal/tree"
+)
+
+// Name is the name registered for the properties codec.
+const Name = "properties"
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// ValueKey is the key the value of a key that is also the parent of other
+// keys is decoded under, a=1 and a.b=2 decode to map[a]map[_value:1 b:2].
+const ValueKey = "_value"
+
+// codec is a Codec implementation with Java properties files. Dotted keys
+// are nested, a.b=1 decodes to map[a]map[b]1, and values are strings.
+// Lists are encoded with their index as key, a.0=x, and the keys 0 to n-1
+// decode back to a list.
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	m, err := tree.ToMap(v)
+	if err != nil {
+		return nil, err
+	}
+	lines := make(map[string]string)
+	flatten("", m, lines)
+	keys := make([]string, 0, len(lines))
+	for k := range lines {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	for _, k := range keys {
+		buf.WriteString(escape(k, true))
+		buf.WriteString(" = ")
+		buf.WriteString(escape(lines[k], false))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+func flatten(prefix string, v interface{}, lines map[string]string) {
+	key := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for k, sub := range vt {
+			if k == ValueKey && prefix != "" {
+				flatten(prefix, sub, lines)
+				continue
+			}
+			flatten(key(k), sub, lines)
+		}
+	case []interface{}:
+		for i, sub := range vt {
+			flatten(key(strconv.Itoa(i)), sub, lines)
+		}
+	case nil:
+		lines[prefix] = ""
+	default:
+		lines[prefix] = fmt.Sprint(vt)
+	}
+}
+
+// escape escapes s for a key or a value, keys also escape the separators.
+func escape(s string, key bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case key && strings.ContainsRune("=: #!", r):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case !key && i == 0 && r == ' ':
+			b.WriteString(`\ `)
+		case !unicode.IsPrint(r) && r <= 0xffff:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	m := make(map[string]interface{})
+	var (
+		logical string
+		lineNo  int
+		cont    bool
+	)
+	add := func() error {
+		k, value, err := parseLine(logical)
+		logical = ""
+		if err == nil {
+			set(m, strings.Split(k, "."), value)
+		}
+		if err != nil {
+			return fmt.Errorf("properties: line %d: %w", lineNo, err)
+		}
+		return nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimLeftFunc(scanner.Text(), unicode.IsSpace)
+		if !cont && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+		// an odd number of trailing backslashes continues the line
+		trailing := len(line) - len(strings.TrimRight(line, `\`))
+		if cont = trailing%2 == 1; cont {
+			logical += line[:len(line)-1]
+			continue
+		}
+		logical += line
+		if err := add(); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if logical != "" {
+		if err := add(); err != nil {
+			return err
+		}
+	}
+	for k, sub := range m {
+		m[k] = toLists(sub)
+	}
+	return tree.Assign(m, v)
+}
+
+// set stores value at the nested keys of path in m, the value of a key that
+// is also the parent of other keys is moved under ValueKey.
+func set(m map[string]interface{}, path []string, value string) {
+	for _, k := range path[:len(path)-1] {
+		switch sub := m[k].(type) {
+		case map[string]interface{}:
+			m = sub
+		case nil:
+			next := make(map[string]interface{})
+			m[k] = next
+			m = next
+		default:
+			next := map[string]interface{}{ValueKey: sub}
+			m[k] = next
+			m = next
+		}
+	}
+	last := path[len(path)-1]
+	if sub, ok := m[last].(map[string]interface{}); ok {
+		sub[ValueKey] = value
+		return
+	}
+	m[last] = value
+}
+
+// toLists replaces the maps under v whose keys are the indexes 0 to n-1 by
+// lists, the way Marshal encodes them.
+func toLists(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	for k, sub := range m {
+		m[k] = toLists(sub)
+	}
+	list := make([]interface{}, len(m))
+	for k, sub := range m {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(m) || strconv.Itoa(i) != k {
+			return m
+		}
+		list[i] = sub
+	}
+	return list
+}
+
+// parseLine splits a logical line into its unescaped key and value, the key
+// ends at the first unescaped =, : or white space.
+func parseLine(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] == '=' || line[i] == ':' || line[i] == ' ' || line[i] == '\t' || line[i] == '\f' {
+			end = i
+			break
+		}
+	}
+	key, err := unescape(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	value, err := unescape(rest)
+	if err != nil {
+		return "", "", err
+	}
+	if key == "" {
+		return "", "", fmt.Errorf("empty key")
+	}
+	return key, value, nil
+}
+
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("invalid unicode escape %s", s[i-1:])
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape %s", s[i-1:i+5])
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+func (codec) Name() string {
+	return Name
+}
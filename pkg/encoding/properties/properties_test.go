@@ -0,0 +1,89 @@
+package properties
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+	doc := `# comment
+! another comment
+app.name = petal
+app.port: 8080
+app.description   a long \
+                  description
+key\ with\ spaces = value\twith\ttabs
+unicode = café
+empty
+servers.0.host = a
+`
+	got := make(map[string]interface{})
+	if err := (codec{}).Unmarshal([]byte(doc), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"app": map[string]interface{}{
+			"name":        "petal",
+			"port":        "8080",
+			"description": "a long description",
+		},
+		"key with spaces": "value\twith\ttabs",
+		"unicode":         "café",
+		"empty":           "",
+		"servers":         []interface{}{map[string]interface{}{"host": "a"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Unmarshal() = %v, want %v", got, want)
+	}
+}
+
+func TestUnmarshalValueAndParent(t *testing.T) {
+	doc := `log4j.rootLogger = INFO, stdout
+log4j.rootLogger.appender = x
+log4j.appender.stdout.layout = PatternLayout
+log4j.appender.stdout = ConsoleAppender
+`
+	got := make(map[string]interface{})
+	if err := (codec{}).Unmarshal([]byte(doc), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"log4j": map[string]interface{}{
+			"rootLogger": map[string]interface{}{ValueKey: "INFO, stdout", "appender": "x"},
+			"appender": map[string]interface{}{
+				"stdout": map[string]interface{}{ValueKey: "ConsoleAppender", "layout": "PatternLayout"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Unmarshal() = %v, want %v", got, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := map[string]interface{}{
+		"app": map[string]interface{}{
+			"name":  "petal",
+			"motto": " leading space, = and : and \\ and\nnewline",
+		},
+		"a=b": "c",
+		"#":   "!",
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a"},
+			map[string]interface{}{"host": "b"},
+		},
+		"ports":  []interface{}{"80", "443"},
+		"logger": map[string]interface{}{ValueKey: "INFO", "appender": "x"},
+	}
+	data, err := (codec{}).Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := (codec{}).Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip changed %v into %v, encoded:\n%s", in, out, data)
+	}
+}
@@ -0,0 +1,73 @@
+package toml
+
+import (
+	"bytes"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/banbridge/common/pkg/encoding"
+)
+
+// Name is the name registered for the toml codec.
+const Name = "toml"
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// codec is a Codec implementation with toml.
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes data into v, arrays of tables decoded into a map are
+// stored as []interface{} like the other codecs do.
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(*map[string]interface{})
+	if !ok {
+		return toml.Unmarshal(data, v)
+	}
+	decoded := make(map[string]interface{})
+	if err := toml.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if *m == nil {
+		*m = make(map[string]interface{}, len(decoded))
+	}
+	for k, sub := range decoded {
+		(*m)[k] = normalize(sub)
+	}
+	return nil
+}
+
+func normalize(v interface{}) interface{} {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for k, sub := range vt {
+			vt[k] = normalize(sub)
+		}
+		return vt
+	case []map[string]interface{}:
+		list := make([]interface{}, len(vt))
+		for i, sub := range vt {
+			list[i] = normalize(sub)
+		}
+		return list
+	case []interface{}:
+		for i, sub := range vt {
+			vt[i] = normalize(sub)
+		}
+		return vt
+	}
+	return v
+}
+
+func (codec) Name() string {
+	return Name
+}
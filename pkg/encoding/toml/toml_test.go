@@ -0,0 +1,68 @@
+package toml
+
+import (
+	"reflect"
+	"testing"
+)
+
+const doc = `name = "petal"
+port = 8080
+debug = true
+
+[db]
+host = "localhost"
+timeout = 1.5
+
+[[servers]]
+host = "a"
+
+[[servers]]
+host = "b"
+`
+
+func TestUnmarshal(t *testing.T) {
+	got := make(map[string]interface{})
+	if err := (codec{}).Unmarshal([]byte(doc), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"name":  "petal",
+		"port":  int64(8080),
+		"debug": true,
+		"db":    map[string]interface{}{"host": "localhost", "timeout": 1.5},
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a"},
+			map[string]interface{}{"host": "b"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Unmarshal() = %v, want %v", got, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var in map[string]interface{}
+	if err := (codec{}).Unmarshal([]byte(doc), &in); err != nil {
+		t.Fatal(err)
+	}
+	data, err := (codec{}).Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := (codec{}).Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip changed %v into %v", in, out)
+	}
+
+	type config struct {
+		Name string `toml:"name"`
+		Port int    `toml:"port"`
+	}
+	var c config
+	if err := (codec{}).Unmarshal(data, &c); err != nil || c.Name != "petal" || c.Port != 8080 {
+		t.Fatalf("Unmarshal() into a struct = %+v %v", c, err)
+	}
+}
@@ -0,0 +1,48 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/banbridge/common/pkg/logs/handler"
+)
+
+// asyncSet is the buffers of the async loggers not closed yet.
+type asyncSet struct {
+	lock     sync.Mutex
+	handlers map[*handler.AsyncHandler]struct{}
+}
+
+var asyncs = &asyncSet{handlers: make(map[*handler.AsyncHandler]struct{})}
+
+func (s *asyncSet) add(h *handler.AsyncHandler) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.handlers[h] = struct{}{}
+}
+
+func (s *asyncSet) remove(h *handler.AsyncHandler) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.handlers, h)
+}
+
+// Sync waits for the records buffered by the async loggers to be written,
+// call it before the process exits.
+func Sync(ctx context.Context) error {
+	asyncs.lock.Lock()
+	handlers := make([]*handler.AsyncHandler, 0, len(asyncs.handlers))
+	for h := range asyncs.handlers {
+		handlers = append(handlers, h)
+	}
+	asyncs.lock.Unlock()
+
+	var errs []error
+	for _, h := range handlers {
+		if err := h.Sync(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,119 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/banbridge/common/pkg/logs/handler"
+)
+
+// syncBuffer is a bytes.Buffer written by the goroutine of an async logger.
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestAsync(t *testing.T) {
+	out := &syncBuffer{}
+	l := NewLogger(
+		WithAsync(handler.AsyncOptions{FlushInterval: time.Hour}),
+		WithOutputs(Output{Format: FormatLogfmt, Writer: out}),
+	)
+	l.With("k", "v").Info("buffered")
+	if strings.Contains(out.String(), "buffered") {
+		t.Fatalf("written before Sync: %q", out.String())
+	}
+	if err := Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "msg=buffered k=v") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	if stats := l.Stats(); stats.Written != 1 || stats.Dropped != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	l.Info("closed")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "msg=closed") {
+		t.Errorf("Close did not flush: %q", out.String())
+	}
+}
+
+func TestAsyncConfig(t *testing.T) {
+	l, err := NewLoggerFromConfig(jsonScanner(`{
+		"outputs": [{"target": "none"}],
+		"async": {"size": 8, "policy": "drop_debug", "flush_interval": "50ms"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.op.Async == nil || *l.op.Async != (handler.AsyncOptions{Size: 8, Policy: handler.OverflowDropDebug, FlushInterval: 50 * time.Millisecond}) {
+		t.Errorf("unexpected async options %+v", l.op.Async)
+	}
+	_ = l.Close()
+
+	for _, bad := range []string{
+		`{"async": {"policy": "drop_newest"}}`,
+		`{"async": {"flush_interval": "soon"}}`,
+	} {
+		if _, err := NewLoggerFromConfig(jsonScanner(bad)); err == nil {
+			t.Errorf("expected an error for %s", bad)
+		}
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	prev, prevDefault := stdLog(), DefaultLogger
+	defer func() {
+		std.Store(prev)
+		DefaultLogger = prevDefault
+	}()
+
+	out := &syncBuffer{}
+	l := NewLogger(
+		WithAsync(handler.AsyncOptions{FlushInterval: time.Hour}),
+		WithOutputs(Output{Format: FormatLogfmt, Writer: out}),
+	)
+	defer l.Close()
+	SetDefault(l)
+
+	Info("package")
+	Named("db").Warn("named")
+	if strings.Contains(out.String(), "package") {
+		t.Fatalf("written before Sync: %q", out.String())
+	}
+	if err := Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "async_test.go:") {
+			t.Errorf("wrong source in %q", line)
+		}
+	}
+	if DefaultLogger.async != l.async {
+		t.Error("DefaultLogger not replaced")
+	}
+}
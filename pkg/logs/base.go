@@ -1,6 +1,9 @@
 package logs
 
-import "context"
+import (
+	"context"
+	"sync/atomic"
+)
 
 type Logger interface {
 	Debug(msg string, args ...any)
@@ -18,39 +21,119 @@ type Logger interface {
 	//CtxPanic(ctx context.Context, msg string, args ...any)
 }
 
+// StructuredLogger is a Logger with the w methods, they log msg as is with
+// the attrs args, key-value pairs or slog.Attr like the args of
+// slog.Logger.Info.
+type StructuredLogger interface {
+	Logger
+
+	Debugw(msg string, args ...any)
+	Warnw(msg string, args ...any)
+	Infow(msg string, args ...any)
+	Errorw(msg string, args ...any)
+
+	CtxDebugw(ctx context.Context, msg string, args ...any)
+	CtxWarnw(ctx context.Context, msg string, args ...any)
+	CtxInfow(ctx context.Context, msg string, args ...any)
+	CtxErrorw(ctx context.Context, msg string, args ...any)
+}
+
 var (
-	stdLog        = NewLogger()
+	std           atomic.Pointer[StdLog]
 	DefaultLogger = NewLogger(WithCallDepth(1))
 )
 
+func init() {
+	std.Store(NewLogger())
+}
+
+// stdLog returns the logger of the package level functions.
+func stdLog() *StdLog {
+	return std.Load()
+}
+
+// SetDefault makes l the logger of the package level functions, Named,
+// With, WithGroup and DefaultLogger, such as a logger of
+// NewLoggerFromConfig writing to a file or asynchronously. The previous
+// logger is not closed and l is still closed by its owner. DefaultLogger
+// is replaced without synchronization, call SetDefault at startup.
+func SetDefault(l *StdLog) {
+	std.Store(l.withCallDepth(1))
+	DefaultLogger = l.withCallDepth(1)
+}
+
 func Info(msg string, args ...any) {
-	stdLog.Info(msg, args...)
+	stdLog().Info(msg, args...)
 }
 
 func Debug(msg string, args ...any) {
-	stdLog.Debug(msg, args...)
+	stdLog().Debug(msg, args...)
 }
 
 func Warn(msg string, args ...any) {
-	stdLog.Warn(msg, args...)
+	stdLog().Warn(msg, args...)
 }
 
 func Error(msg string, args ...any) {
-	stdLog.Error(msg, args...)
+	stdLog().Error(msg, args...)
 }
 
 func CtxInfo(ctx context.Context, msg string, args ...any) {
-	stdLog.CtxInfo(ctx, msg, args...)
+	stdLog().CtxInfo(ctx, msg, args...)
 }
 
 func CtxDebug(ctx context.Context, msg string, args ...any) {
-	stdLog.CtxDebug(ctx, msg, args...)
+	stdLog().CtxDebug(ctx, msg, args...)
 }
 
 func CtxWarn(ctx context.Context, msg string, args ...any) {
-	stdLog.CtxWarn(ctx, msg, args...)
+	stdLog().CtxWarn(ctx, msg, args...)
 }
 
 func CtxError(ctx context.Context, msg string, args ...any) {
-	stdLog.CtxError(ctx, msg, args...)
+	stdLog().CtxError(ctx, msg, args...)
+}
+
+func Infow(msg string, args ...any) {
+	stdLog().Infow(msg, args...)
+}
+
+func Debugw(msg string, args ...any) {
+	stdLog().Debugw(msg, args...)
+}
+
+func Warnw(msg string, args ...any) {
+	stdLog().Warnw(msg, args...)
+}
+
+func Errorw(msg string, args ...any) {
+	stdLog().Errorw(msg, args...)
+}
+
+func CtxInfow(ctx context.Context, msg string, args ...any) {
+	stdLog().CtxInfow(ctx, msg, args...)
+}
+
+func CtxDebugw(ctx context.Context, msg string, args ...any) {
+	stdLog().CtxDebugw(ctx, msg, args...)
+}
+
+func CtxWarnw(ctx context.Context, msg string, args ...any) {
+	stdLog().CtxWarnw(ctx, msg, args...)
+}
+
+func CtxErrorw(ctx context.Context, msg string, args ...any) {
+	stdLog().CtxErrorw(ctx, msg, args...)
+}
+
+// With returns a logger writing to the outputs of the package level
+// functions and adding args to every record, see StdLog.With.
+func With(args ...any) *StdLog {
+	return stdLog().direct().With(args...)
+}
+
+// WithGroup returns a logger writing to the outputs of the package level
+// functions in the group name, see StdLog.WithGroup.
+func WithGroup(name string) *StdLog {
+	return stdLog().direct().WithGroup(name)
 }
@@ -0,0 +1,138 @@
+package logs
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/banbridge/common/pkg/logs/handler"
+)
+
+// Config is the log settings of a config subtree, such as
+//
+//	log:
+//	  level: debug
+//	  outputs:
+//	    - target: stdout
+//	      format: text
+//	    - target: file
+//	      format: json
+//	      file:
+//	        path: logs/app.log
+//	        max_size: 200
+//	        max_backups: 50
+//	        compress: true
+//	  levels:
+//	    config: debug
+//	  async:
+//	    size: 4096
+//	    policy: drop_debug
+//	    flush_interval: 200ms
+//
+// Empty fields keep the defaults of NewLogger.
+type Config struct {
+	// Level is debug, info, warn or error, with an optional offset like info+2.
+	Level   string   `json:"level"`
+	Outputs []Output `json:"outputs"`
+	// Levels are the levels of the named loggers, see SetNamedLevel.
+	Levels map[string]string `json:"levels"`
+	// Async writes the records from a goroutine when it is set.
+	Async *AsyncConfig `json:"async"`
+}
+
+// AsyncConfig is the settings of handler.AsyncOptions.
+type AsyncConfig struct {
+	Size int `json:"size"`
+	// Policy is block, drop_oldest or drop_debug.
+	Policy handler.OverflowPolicy `json:"policy"`
+	// FlushInterval is a duration like 100ms.
+	FlushInterval string `json:"flush_interval"`
+}
+
+// Scanner decodes a config subtree, config.Value implements it.
+type Scanner interface {
+	Scan(v interface{}) error
+}
+
+// parseLevel parses a level such as debug or info+2.
+func parseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("logs: %w", err)
+	}
+	return level, nil
+}
+
+// ApplyLevels sets the level of SetLevel to Level when it is not empty and
+// replaces the levels of the named loggers with Levels when it is set, so
+// the levels changed at runtime are kept by a Config without them. Nothing
+// is changed if a level is invalid.
+func (c *Config) ApplyLevels() error {
+	overrides := make(map[string]slog.Level, len(c.Levels))
+	for name, s := range c.Levels {
+		level, err := parseLevel(s)
+		if err != nil {
+			return fmt.Errorf("%w for %s", err, name)
+		}
+		overrides[name] = level
+	}
+	if c.Level != "" {
+		level, err := parseLevel(c.Level)
+		if err != nil {
+			return err
+		}
+		SetLevel(level)
+	}
+	if c.Levels != nil {
+		SetNamedLevels(overrides)
+	}
+	return nil
+}
+
+// Options returns the logger options of the outputs and async settings,
+// Level and Levels are set in the registry by ApplyLevels so the loggers
+// keep following SetLevel.
+func (c *Config) Options() ([]LoggerOption, error) {
+	var opts []LoggerOption
+	if c.Outputs != nil {
+		for _, o := range c.Outputs {
+			if err := o.validate(); err != nil {
+				return nil, err
+			}
+		}
+		opts = append(opts, WithOutputs(c.Outputs...))
+	}
+	if c.Async != nil {
+		if err := c.Async.Policy.Validate(); err != nil {
+			return nil, fmt.Errorf("logs: %w", err)
+		}
+		async := handler.AsyncOptions{Size: c.Async.Size, Policy: c.Async.Policy}
+		if c.Async.FlushInterval != "" {
+			d, err := time.ParseDuration(c.Async.FlushInterval)
+			if err != nil {
+				return nil, fmt.Errorf("logs: async flush interval: %w", err)
+			}
+			async.FlushInterval = d
+		}
+		opts = append(opts, WithAsync(async))
+	}
+	return opts, nil
+}
+
+// NewLoggerFromConfig new a logger with the settings scanned from v, such as
+// the value of the log key of a config.Config. opts are applied after them.
+// Level and Levels are applied to the registry, see ApplyLevels.
+func NewLoggerFromConfig(v Scanner, opts ...LoggerOption) (*StdLog, error) {
+	var c Config
+	if err := v.Scan(&c); err != nil {
+		return nil, fmt.Errorf("logs: scan config: %w", err)
+	}
+	configured, err := c.Options()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.ApplyLevels(); err != nil {
+		return nil, err
+	}
+	return NewLogger(append(configured, opts...)...), nil
+}
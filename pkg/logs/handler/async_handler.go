@@ -0,0 +1,394 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy is what an AsyncHandler does with a record when its
+// buffer is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits for the buffer to have room, nothing is lost.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest drops the oldest buffered record.
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowDropDebug drops the oldest buffered record below info, or the
+	// new record if it is below info, or the oldest record otherwise.
+	OverflowDropDebug OverflowPolicy = "drop_debug"
+)
+
+// AsyncOptions configures an AsyncHandler.
+type AsyncOptions struct {
+	// Size is the number of records buffered, 1024 when 0.
+	Size int
+	// Policy is OverflowBlock when empty.
+	Policy OverflowPolicy
+	// FlushInterval is how often the buffer is written, 100ms when 0. The
+	// buffer is also written as soon as it is half full.
+	FlushInterval time.Duration
+}
+
+// AsyncStats counts the records of an AsyncHandler.
+type AsyncStats struct {
+	// Buffered is the number of records waiting to be written.
+	Buffered int
+	Written  uint64
+	// Dropped is the number of records dropped by the overflow policy.
+	Dropped uint64
+	// Failed is the number of records the wrapped handler failed to write.
+	Failed uint64
+}
+
+// AsyncHandler buffers the records in a bounded ring and writes them to
+// the wrapped handler from a goroutine, so a slow output does not block
+// the callers unless the policy is OverflowBlock.
+type AsyncHandler struct {
+	handler slog.Handler
+	state   *asyncState
+}
+
+// asyncEntry is a buffered record and the handler it is written to, the
+// handlers of WithAttrs and WithGroup share the buffer.
+type asyncEntry struct {
+	handler slog.Handler
+	record  slog.Record
+}
+
+type asyncState struct {
+	opts AsyncOptions
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	ring    []asyncEntry
+	head    int
+	n       int
+	closed  bool
+	stats   AsyncStats
+	// started and finished count the batches, a batch writes all the
+	// records buffered when it starts.
+	started, finished uint64
+	// batchDone is closed when a batch finishes.
+	batchDone chan struct{}
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewAsyncHandler returns a handler writing to h from a goroutine, Close
+// stops it.
+func NewAsyncHandler(h slog.Handler, opts *AsyncOptions) *AsyncHandler {
+	s := &asyncState{
+		batchDone: make(chan struct{}),
+		wake:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Size <= 0 {
+		s.opts.Size = 1024
+	}
+	if s.opts.Policy == "" {
+		s.opts.Policy = OverflowBlock
+	}
+	if s.opts.FlushInterval <= 0 {
+		s.opts.FlushInterval = 100 * time.Millisecond
+	}
+	s.notFull = sync.NewCond(&s.mu)
+	s.ring = make([]asyncEntry, s.opts.Size)
+	go s.run()
+	return &AsyncHandler{handler: h, state: s}
+}
+
+// Validate reports the policies that are not supported.
+func (p OverflowPolicy) Validate() error {
+	switch p {
+	case OverflowBlock, OverflowDropOldest, OverflowDropDebug, "":
+		return nil
+	}
+	return fmt.Errorf("unknown overflow policy %q", p)
+}
+
+func (a *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return a.handler.Enabled(ctx, level)
+}
+
+// Handle buffers a copy of the record with its attrs resolved, it writes it
+// synchronously once the handler is closed.
+func (a *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	s := a.state
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return a.handler.Handle(ctx, r)
+	}
+	if s.n == len(s.ring) && !s.makeRoom(r.Level) {
+		s.mu.Unlock()
+		return nil
+	}
+	if s.closed {
+		// closed while waiting for room
+		s.mu.Unlock()
+		return a.handler.Handle(ctx, r)
+	}
+	s.ring[(s.head+s.n)%len(s.ring)] = asyncEntry{handler: a.handler, record: resolveRecord(r)}
+	s.n++
+	half := s.n >= len(s.ring)/2
+	s.mu.Unlock()
+	if half {
+		s.signal()
+	}
+	return nil
+}
+
+// maxSnapshotDepth is how deep the maps, slices and pointers of an attr
+// are copied, deeper values are shared with the caller.
+const maxSnapshotDepth = 8
+
+// resolveRecord returns a copy of r with the LogValuers of its attrs
+// resolved and their maps, slices and pointers copied, so the writer
+// goroutine logs the values of the Handle call and does not read memory
+// the caller keeps changing.
+func resolveRecord(r slog.Record) slog.Record {
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, resolveAttr(a))
+		return true
+	})
+	out.AddAttrs(attrs...)
+	return out
+}
+
+func resolveAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = resolveAttr(ga)
+		}
+		v = slog.GroupValue(attrs...)
+	case slog.KindAny:
+		if rv := reflect.ValueOf(v.Any()); rv.IsValid() {
+			v = slog.AnyValue(snapshot(rv, maxSnapshotDepth).Interface())
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// snapshot returns a copy of v of the same type, the maps, slices and
+// pointers under it are copied up to depth levels.
+func snapshot(v reflect.Value, depth int) reflect.Value {
+	if depth == 0 {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(snapshot(v.Elem(), depth-1))
+		return p
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		return snapshot(v.Elem(), depth)
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(snapshot(v.Index(i), depth-1))
+		}
+		return s
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), snapshot(iter.Value(), depth-1))
+		}
+		return m
+	}
+	return v
+}
+
+// makeRoom frees a slot of the full ring according to the policy, it
+// returns false if the new record of level is dropped instead. s.mu must
+// be held.
+func (s *asyncState) makeRoom(level slog.Level) bool {
+	switch s.opts.Policy {
+	case OverflowDropOldest:
+		s.remove(0)
+	case OverflowDropDebug:
+		for i := 0; i < s.n; i++ {
+			if s.ring[(s.head+i)%len(s.ring)].record.Level < slog.LevelInfo {
+				s.remove(i)
+				return true
+			}
+		}
+		if level < slog.LevelInfo {
+			s.stats.Dropped++
+			return false
+		}
+		s.remove(0)
+	default:
+		for s.n == len(s.ring) && !s.closed {
+			s.signal()
+			s.notFull.Wait()
+		}
+	}
+	return true
+}
+
+// remove drops the i-th buffered record. s.mu must be held.
+func (s *asyncState) remove(i int) {
+	size := len(s.ring)
+	for ; i > 0; i-- {
+		s.ring[(s.head+i)%size] = s.ring[(s.head+i-1)%size]
+	}
+	s.ring[s.head] = asyncEntry{}
+	s.head = (s.head + 1) % size
+	s.n--
+	s.stats.Dropped++
+}
+
+func (s *asyncState) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *asyncState) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+	var batch []asyncEntry
+	for {
+		select {
+		case <-s.wake:
+		case <-ticker.C:
+		case <-s.stop:
+			s.flush(batch)
+			return
+		}
+		batch = s.flush(batch)
+	}
+}
+
+// flush writes the buffered records, batch is reused between the calls.
+func (s *asyncState) flush(batch []asyncEntry) []asyncEntry {
+	s.mu.Lock()
+	s.started++
+	batch = batch[:0]
+	for i := 0; i < s.n; i++ {
+		j := (s.head + i) % len(s.ring)
+		batch = append(batch, s.ring[j])
+		s.ring[j] = asyncEntry{}
+	}
+	s.head, s.n = 0, 0
+	s.notFull.Broadcast()
+	s.mu.Unlock()
+
+	var failed uint64
+	for _, e := range batch {
+		if err := e.handler.Handle(context.Background(), e.record); err != nil {
+			failed++
+		}
+	}
+	clear(batch)
+
+	s.mu.Lock()
+	s.stats.Written += uint64(len(batch)) - failed
+	s.stats.Failed += failed
+	s.finished++
+	close(s.batchDone)
+	s.batchDone = make(chan struct{})
+	s.mu.Unlock()
+	return batch
+}
+
+// Sync waits for the records buffered before the call to be written.
+func (a *AsyncHandler) Sync(ctx context.Context) error {
+	s := a.state
+	s.mu.Lock()
+	want := s.started + 1
+	s.mu.Unlock()
+	for {
+		s.mu.Lock()
+		finished, closed, ch := s.finished, s.closed, s.batchDone
+		s.mu.Unlock()
+		if finished >= want {
+			return nil
+		}
+		if closed {
+			// the last batch is written by Close
+			ch = s.done
+		} else {
+			s.signal()
+		}
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if closed {
+			return nil
+		}
+	}
+}
+
+// Close writes the buffered records and stops the goroutine, the records
+// handled afterwards are written synchronously.
+func (a *AsyncHandler) Close(ctx context.Context) error {
+	s := a.state
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.stop)
+		s.notFull.Broadcast()
+	}
+	s.mu.Unlock()
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns the counters of the records.
+func (a *AsyncHandler) Stats() AsyncStats {
+	s := a.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats
+	stats.Buffered = s.n
+	return stats
+}
+
+func (a *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{handler: a.handler.WithAttrs(attrs), state: a.state}
+}
+
+func (a *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{handler: a.handler.WithGroup(name), state: a.state}
+}
+
+var _ slog.Handler = &AsyncHandler{}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedHandler records the messages once release is closed, entered
+// receives a value when a write starts.
+type gatedHandler struct {
+	entered chan struct{}
+	release chan struct{}
+
+	mu   sync.Mutex
+	msgs []string
+}
+
+func newGatedHandler() *gatedHandler {
+	return &gatedHandler{entered: make(chan struct{}, 1), release: make(chan struct{})}
+}
+
+func (h *gatedHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *gatedHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *gatedHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *gatedHandler) Handle(_ context.Context, r slog.Record) error {
+	select {
+	case h.entered <- struct{}{}:
+	default:
+	}
+	<-h.release
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.msgs = append(h.msgs, r.Message)
+	return nil
+}
+
+func (h *gatedHandler) messages() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.msgs...)
+}
+
+func record(level slog.Level, msg string) slog.Record {
+	return slog.NewRecord(time.Now(), level, msg, 0)
+}
+
+func TestAsyncHandlerPolicies(t *testing.T) {
+	info, debug := slog.LevelInfo, slog.LevelDebug
+	for _, tc := range []struct {
+		policy  OverflowPolicy
+		records []slog.Record
+		want    []string
+		dropped uint64
+	}{
+		{
+			policy:  OverflowDropOldest,
+			records: []slog.Record{record(info, "2"), record(info, "3"), record(info, "4")},
+			want:    []string{"1", "3", "4"},
+			dropped: 1,
+		},
+		{
+			policy:  OverflowDropDebug,
+			records: []slog.Record{record(debug, "2"), record(info, "3"), record(info, "4"), record(debug, "5")},
+			want:    []string{"1", "3", "4"},
+			dropped: 2,
+		},
+		{
+			policy:  OverflowDropDebug,
+			records: []slog.Record{record(info, "2"), record(info, "3"), record(info, "4")},
+			want:    []string{"1", "3", "4"},
+			dropped: 1,
+		},
+		{
+			policy:  OverflowBlock,
+			records: []slog.Record{record(info, "2"), record(info, "3"), record(info, "4")},
+			want:    []string{"1", "2", "3", "4"},
+		},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			g := newGatedHandler()
+			h := NewAsyncHandler(g, &AsyncOptions{Size: 2, Policy: tc.policy, FlushInterval: time.Hour})
+			ctx := context.Background()
+
+			// the first record is taken by the goroutine, which blocks on it
+			_ = h.Handle(ctx, record(info, "1"))
+			<-g.entered
+
+			handled := make(chan struct{})
+			go func() {
+				defer close(handled)
+				for _, r := range tc.records {
+					_ = h.Handle(ctx, r)
+				}
+			}()
+			if tc.policy == OverflowBlock {
+				select {
+				case <-handled:
+					t.Fatal("Handle did not block on a full buffer")
+				case <-time.After(50 * time.Millisecond):
+				}
+			} else {
+				<-handled
+			}
+			close(g.release)
+			<-handled
+			if err := h.Sync(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if got := g.messages(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("written want: %v, got: %v", tc.want, got)
+			}
+			stats := h.Stats()
+			if stats.Dropped != tc.dropped || stats.Written != uint64(len(tc.want)) || stats.Buffered != 0 {
+				t.Errorf("unexpected stats %+v", stats)
+			}
+			if err := h.Close(ctx); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestAsyncHandlerClose(t *testing.T) {
+	g := newGatedHandler()
+	close(g.release)
+	h := NewAsyncHandler(g, &AsyncOptions{Size: 16, FlushInterval: time.Hour})
+	l := slog.New(h).With("k", "v")
+	l.Info("buffered")
+	if got := g.messages(); len(got) != 0 {
+		t.Fatalf("written before a flush: %v", got)
+	}
+	if err := h.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("after close")
+	if err := h.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.messages(); !reflect.DeepEqual(got, []string{"buffered", "after close"}) {
+		t.Errorf("unexpected messages %v", got)
+	}
+}
+
+func TestAsyncHandlerSyncTimeout(t *testing.T) {
+	g := newGatedHandler()
+	defer close(g.release)
+	h := NewAsyncHandler(g, &AsyncOptions{FlushInterval: time.Hour})
+	_ = h.Handle(context.Background(), record(slog.LevelInfo, "stuck"))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := h.Sync(ctx); err != context.DeadlineExceeded {
+		t.Errorf("want: %v, got: %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestAsyncHandlerInterval(t *testing.T) {
+	g := newGatedHandler()
+	close(g.release)
+	h := NewAsyncHandler(g, &AsyncOptions{FlushInterval: 10 * time.Millisecond})
+	defer h.Close(context.Background())
+	_ = h.Handle(context.Background(), record(slog.LevelInfo, "ticked"))
+	deadline := time.Now().Add(time.Second)
+	for len(g.messages()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := g.messages(); !reflect.DeepEqual(got, []string{"ticked"}) {
+		t.Errorf("unexpected messages %v", got)
+	}
+}
+
+// counter logs the value of n when it is resolved.
+type counter struct{ n int }
+
+func (c *counter) LogValue() slog.Value { return slog.IntValue(c.n) }
+
+type point struct{ X int }
+
+func TestAsyncHandlerResolvesValues(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewAsyncHandler(slog.NewJSONHandler(&buf, nil), &AsyncOptions{Size: 16, FlushInterval: time.Hour})
+	c := &counter{n: 1}
+	data := []byte("abc")
+	tags := map[string]interface{}{"k": "v", "nested": []string{"a"}}
+	p := &point{X: 1}
+	slog.New(h).Info("msg", "counter", c, "data", data, slog.Group("g", "tags", tags, "point", p))
+
+	c.n = 2
+	data[0] = 'x'
+	tags["k"] = "changed"
+	tags["nested"].([]string)[0] = "b"
+	p.X = 2
+	if err := h.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("%v: %s", err, buf.Bytes())
+	}
+	want := map[string]interface{}{
+		"counter": float64(1),
+		"data":    "YWJj",
+		"g": map[string]interface{}{
+			"tags":  map[string]interface{}{"k": "v", "nested": []interface{}{"a"}},
+			"point": map[string]interface{}{"X": float64(1)},
+		},
+	}
+	for k, v := range want {
+		if !reflect.DeepEqual(got[k], v) {
+			t.Errorf("%s want: %v, got: %v", k, v, got[k])
+		}
+	}
+}
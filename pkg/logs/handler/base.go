@@ -1,14 +1,13 @@
 package handler
 
 import (
-	"fmt"
-	"path"
+	"log/slog"
 	"runtime"
 )
 
-func getCallInfo(pc uintptr) string {
+// getSource returns the location of pc.
+func getSource(pc uintptr) *slog.Source {
 	fs := runtime.CallersFrames([]uintptr{pc})
 	f, _ := fs.Next()
-	file := path.Base(f.File)
-	return fmt.Sprintf("%s:%d", file, f.Line)
+	return &slog.Source{Function: f.Function, File: f.File, Line: f.Line}
 }
@@ -4,7 +4,12 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"unicode"
 
 	"github.com/fatih/color"
 	"log/slog"
@@ -12,24 +17,47 @@ import (
 	"github.com/banbridge/common/pkg/consts"
 )
 
+// ConsoleHandler writes lines for a terminal, colored unless NoColor is set,
+//
+//	2006/01/02 15:04:05.000000 main.go:12 [INFO] msg=started g.key=value
+//
+// the attrs of the groups are written with their keys joined by dots.
 type ConsoleHandler struct {
 	opts ConsoleOptions
-	// TODO: state for WithGroup and WithAttrs
-	mu  *sync.Mutex
-	out io.Writer
+	// attrs are the attrs of WithAttrs, formatted.
+	attrs []byte
+	// groups are the groups of WithGroup.
+	groups []string
+	mu     *sync.Mutex
+	out    io.Writer
 }
 
 type ConsoleOptions struct {
+	// AddSource writes the file and line of the log call.
+	AddSource bool
+
 	// Level reports the minimum level to log.
 	// Levels with lower levels are discarded.
 	// If nil, the Handler uses [slog.LevelInfo].
 	Level slog.Leveler
+
+	// ReplaceAttr rewrites the attrs before they are written, as
+	// [slog.HandlerOptions.ReplaceAttr]. It is called for the time, level,
+	// source and message of the records too, with nil groups.
+	ReplaceAttr func(groups []string, a slog.Attr) slog.Attr
+
+	// NoColor writes the lines without color codes, for the writers that
+	// are not a terminal.
+	NoColor bool
 }
 
+// NewConsoleHandler returns a handler writing to w, nil opts write the
+// source of the records at slog.LevelInfo.
 func NewConsoleHandler(w io.Writer, opts *ConsoleOptions) *ConsoleHandler {
 	h := &ConsoleHandler{
-		mu:  &sync.Mutex{},
-		out: w,
+		mu:   &sync.Mutex{},
+		out:  w,
+		opts: ConsoleOptions{AddSource: true},
 	}
 	if opts != nil {
 		h.opts = *opts
@@ -54,29 +82,52 @@ var colorFuncMap = map[slog.Level]func(format string, a ...any) string{
 func (c *ConsoleHandler) Handle(ctx context.Context, r slog.Record) error {
 	buf := new(bytes.Buffer)
 
-	timeStr := r.Time.Format(consts.TimeWithMS)
-	buf.WriteString(timeStr)
-	buf.WriteString(" ")
+	if !r.Time.IsZero() {
+		if a, ok := c.replace(slog.Time(slog.TimeKey, r.Time)); ok {
+			if a.Value.Kind() == slog.KindTime {
+				buf.WriteString(a.Value.Time().Format(consts.TimeWithMS))
+			} else {
+				buf.WriteString(a.Value.String())
+			}
+			buf.WriteString(" ")
+		}
+	}
 
-	level := r.Level.String()
-	if fn, ok := colorFuncMap[r.Level]; ok {
-		level = fn(level)
+	if c.opts.AddSource && r.PC != 0 {
+		if a, ok := c.replace(slog.Any(slog.SourceKey, getSource(r.PC))); ok {
+			if src, ok := a.Value.Any().(*slog.Source); ok {
+				buf.WriteString(path.Base(src.File) + ":" + strconv.Itoa(src.Line))
+			} else {
+				buf.WriteString(a.Value.String())
+			}
+			buf.WriteString(" ")
+		}
 	}
 
-	if r.PC != 0 {
-		buf.WriteString(getCallInfo(r.PC))
+	if a, ok := c.replace(slog.Any(slog.LevelKey, r.Level)); ok {
+		level := a.Value.String()
+		if l, ok := a.Value.Any().(slog.Level); ok {
+			if fn, ok := colorFuncMap[l]; ok && !c.opts.NoColor {
+				level = fn(level)
+			}
+		}
+		buf.WriteString("[" + level + "]")
 		buf.WriteString(" ")
 	}
 
-	buf.WriteString("[" + level + "]")
-	buf.WriteString(" ")
-
-	msg := color.CyanString(r.Message)
-	buf.WriteString(slog.String(slog.MessageKey, msg).String())
-	buf.WriteString(" ")
+	if a, ok := c.replace(slog.String(slog.MessageKey, r.Message)); ok {
+		buf.WriteString(a.Key + "=")
+		msg := quote(a.Value.String())
+		if !c.opts.NoColor {
+			msg = color.CyanString(msg)
+		}
+		buf.WriteString(msg)
+		buf.WriteString(" ")
+	}
 
+	buf.Write(c.attrs)
 	r.Attrs(func(attr slog.Attr) bool {
-		buf.WriteString(attr.String() + " ")
+		c.appendAttr(buf, c.groups, attr)
 		return true
 	})
 
@@ -88,14 +139,73 @@ func (c *ConsoleHandler) Handle(ctx context.Context, r slog.Record) error {
 	return err
 }
 
+// replace applies ReplaceAttr to a builtin attr, ok is false if it is removed.
+func (c *ConsoleHandler) replace(a slog.Attr) (slog.Attr, bool) {
+	if c.opts.ReplaceAttr == nil {
+		return a, true
+	}
+	a = c.opts.ReplaceAttr(nil, a)
+	a.Value = a.Value.Resolve()
+	return a, !a.Equal(slog.Attr{})
+}
+
+// appendAttr writes attr with its key qualified by groups, the attrs of a
+// group attr are written one by one and an empty group writes nothing.
+func (c *ConsoleHandler) appendAttr(buf *bytes.Buffer, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if c.opts.ReplaceAttr != nil && a.Value.Kind() != slog.KindGroup {
+		a = c.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(slices.Clip(groups), a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			c.appendAttr(buf, groups, ga)
+		}
+		return
+	}
+	for _, g := range groups {
+		buf.WriteString(g + ".")
+	}
+	buf.WriteString(a.Key + "=" + quote(a.Value.String()) + " ")
+}
+
+// quote quotes s if it is empty or has spaces, quotes, equal signs or
+// characters that are not printable.
+func quote(s string) string {
+	if s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return r == ' ' || r == '"' || r == '=' || !unicode.IsPrint(r)
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
 func (c *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	//TODO implement me
-	panic("implement me")
+	if len(attrs) == 0 {
+		return c
+	}
+	h := *c
+	buf := bytes.NewBuffer(slices.Clip(c.attrs))
+	for _, attr := range attrs {
+		h.appendAttr(buf, c.groups, attr)
+	}
+	h.attrs = buf.Bytes()
+	return &h
 }
 
 func (c *ConsoleHandler) WithGroup(name string) slog.Handler {
-	//TODO implement me
-	panic("implement me")
+	if name == "" {
+		return c
+	}
+	h := *c
+	h.groups = append(slices.Clip(c.groups), name)
+	return &h
 }
 
 var _ slog.Handler = &ConsoleHandler{}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+var (
+	consoleLine = regexp.MustCompile(`^(?:(\d{4}/\d\d/\d\d \d\d:\d\d:\d\d\.\d{6}) )?(?:(\S+:\d+) )?(?:\[(\S+)\] )?(?:msg=("(?:[^"\\]|\\.)*"|\S*) )?(.*)$`)
+	consoleAttr = regexp.MustCompile(`(\S*?)=("(?:[^"\\]|\\.)*"|\S*) `)
+)
+
+// parseConsole parses the lines of a ConsoleHandler, the dotted keys of
+// the groups are nested.
+func parseConsole(t *testing.T, out string) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		m := consoleLine.FindStringSubmatch(line)
+		if m == nil {
+			t.Fatalf("unexpected line %q", line)
+		}
+		record := make(map[string]any)
+		for i, key := range []string{slog.TimeKey, slog.SourceKey, slog.LevelKey, slog.MessageKey} {
+			if m[i+1] != "" {
+				record[key] = unquote(m[i+1])
+			}
+		}
+		for _, kv := range consoleAttr.FindAllStringSubmatch(m[5], -1) {
+			keys := strings.Split(kv[1], ".")
+			group := record
+			for _, k := range keys[:len(keys)-1] {
+				g, ok := group[k].(map[string]any)
+				if !ok {
+					g = make(map[string]any)
+					group[k] = g
+				}
+				group = g
+			}
+			group[keys[len(keys)-1]] = unquote(kv[2])
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
+
+func TestConsoleHandlerSlogtest(t *testing.T) {
+	color.NoColor = true
+	var buf bytes.Buffer
+	h := NewConsoleHandler(&buf, &ConsoleOptions{AddSource: true})
+	if err := slogtest.TestHandler(h, func() []map[string]any {
+		return parseConsole(t, buf.String())
+	}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConsoleHandlerReplaceAttr(t *testing.T) {
+	color.NoColor = true
+	var buf bytes.Buffer
+	h := NewConsoleHandler(&buf, &ConsoleOptions{
+		AddSource: true,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch {
+			case a.Key == slog.TimeKey || a.Key == slog.SourceKey:
+				return slog.Attr{}
+			case a.Key == "password":
+				return slog.String(a.Key, "***")
+			case len(groups) > 0 && a.Key == "id":
+				return slog.String(a.Key, strings.Join(groups, "/")+":"+a.Value.String())
+			}
+			return a
+		},
+	})
+	slog.New(h).With("password", "secret").WithGroup("req").
+		Info("login done", "id", 7, slog.Group("user", "id", 8), "at", time.Time{})
+
+	want := `[INFO] msg="login done" password=*** req.id=req:7 req.user.id=req/user:8 req.at="0001-01-01 00:00:00 +0000 UTC" ` + "\n"
+	if buf.String() != want {
+		t.Errorf("got %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestConsoleHandlerLevel(t *testing.T) {
+	var level slog.LevelVar
+	h := NewConsoleHandler(&bytes.Buffer{}, &ConsoleOptions{Level: &level})
+	if h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("debug enabled at info")
+	}
+	level.Set(slog.LevelDebug)
+	if !h.WithGroup("g").Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("debug disabled at debug")
+	}
+}
+
+func TestConsoleHandlerDefaultSource(t *testing.T) {
+	color.NoColor = true
+	var buf bytes.Buffer
+	slog.New(NewConsoleHandler(&buf, nil)).Info("here")
+	if !strings.Contains(buf.String(), " console_handler_test.go:") {
+		t.Errorf("no source in %q", buf.String())
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 
 	"log/slog"
 )
@@ -18,13 +19,19 @@ func (m MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
 	return false
 }
 
+// Handle sends a clone of the record to the handlers enabled for its level,
+// a failing handler does not stop the others and the errors are joined.
 func (m MultiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
 	for _, h := range m {
-		if err := h.Handle(ctx, record); err != nil {
-			return err
+		if !h.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, record.Clone()); err != nil {
+			errs = append(errs, err)
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 func (m MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
@@ -36,6 +43,9 @@ func (m MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 }
 
 func (m MultiHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return m
+	}
 	handlers := make([]slog.Handler, len(m))
 	for i, h := range m {
 		handlers[i] = h.WithGroup(name)
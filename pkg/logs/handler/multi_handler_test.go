@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+)
+
+func TestMultiHandlerSlogtest(t *testing.T) {
+	var info, debug bytes.Buffer
+	h := MultiHandler{
+		slog.NewJSONHandler(&info, nil),
+		slog.NewJSONHandler(&debug, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	}
+	if err := slogtest.TestHandler(h, func() []map[string]any {
+		var records []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(info.Bytes()), []byte("\n")) {
+			var m map[string]any
+			if err := json.Unmarshal(line, &m); err != nil {
+				t.Fatal(err)
+			}
+			records = append(records, m)
+		}
+		return records
+	}); err != nil {
+		t.Error(err)
+	}
+	if info.String() != debug.String() {
+		t.Errorf("outputs differ:\n%s\n%s", info.String(), debug.String())
+	}
+
+	info.Reset()
+	debug.Reset()
+	slog.New(h).Debug("debug only")
+	if info.Len() != 0 || !strings.Contains(debug.String(), "debug only") {
+		t.Errorf("debug record not fanned out by level: %q %q", info.String(), debug.String())
+	}
+}
+
+// failingHandler fails every record and counts them.
+type failingHandler struct {
+	slog.Handler
+	handled *int
+}
+
+func (h failingHandler) Handle(context.Context, slog.Record) error {
+	*h.handled++
+	return errors.New("disk full")
+}
+
+func TestMultiHandlerErrors(t *testing.T) {
+	var buf bytes.Buffer
+	handled := 0
+	h := MultiHandler{
+		failingHandler{Handler: slog.NewTextHandler(&buf, nil), handled: &handled},
+		slog.NewTextHandler(&buf, nil),
+		failingHandler{Handler: slog.NewTextHandler(&buf, nil), handled: &handled},
+	}
+	var r slog.Record
+	r.Level = slog.LevelInfo
+	r.Message = "msg"
+	err := h.Handle(context.Background(), r)
+	if err == nil || handled != 2 || !strings.Contains(buf.String(), "msg=msg") {
+		t.Errorf("err: %v, handled: %d, output: %q", err, handled, buf.String())
+	}
+}
@@ -0,0 +1,139 @@
+package logs
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+)
+
+// levelAll lets the handlers of the outputs pass every record, the
+// loggers gate them with their own level.
+const levelAll = slog.Level(math.MinInt32)
+
+// levelRegistry holds the level of the loggers created without WithLevel
+// and the overrides of the named loggers, an override of a name also
+// applies to the names under it: config overrides config.file.
+type levelRegistry struct {
+	lock      sync.Mutex
+	root      slog.LevelVar
+	overrides map[string]slog.Level
+	named     map[string]*slog.LevelVar
+}
+
+var levels = &levelRegistry{
+	overrides: make(map[string]slog.Level),
+	named:     make(map[string]*slog.LevelVar),
+}
+
+// leveler returns the level of the logger named name, the root level for
+// an empty name.
+func (r *levelRegistry) leveler(name string) slog.Leveler {
+	if name == "" {
+		return &r.root
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	v, ok := r.named[name]
+	if !ok {
+		v = new(slog.LevelVar)
+		v.Set(r.effective(name))
+		r.named[name] = v
+	}
+	return v
+}
+
+// effective returns the override of name or of the closest name above
+// it, or the root level, r.lock must be held.
+func (r *levelRegistry) effective(name string) slog.Level {
+	for n := name; n != ""; {
+		if l, ok := r.overrides[n]; ok {
+			return l
+		}
+		i := strings.LastIndexByte(n, '.')
+		if i < 0 {
+			break
+		}
+		n = n[:i]
+	}
+	return r.root.Level()
+}
+
+// update changes the levels with fn and refreshes the named loggers.
+func (r *levelRegistry) update(fn func()) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	fn()
+	for name, v := range r.named {
+		v.Set(r.effective(name))
+	}
+}
+
+// SetLevel sets the level of the loggers created without WithLevel, such
+// as the package level functions and DefaultLogger, and of the named
+// loggers without override.
+func SetLevel(level slog.Level) {
+	levels.update(func() { levels.root.Set(level) })
+}
+
+// GetLevel returns the level set by SetLevel, slog.LevelInfo by default.
+func GetLevel() slog.Level {
+	return levels.root.Level()
+}
+
+// SetNamedLevel overrides the level of the loggers named name and of the
+// names under it.
+func SetNamedLevel(name string, level slog.Level) {
+	levels.update(func() { levels.overrides[name] = level })
+}
+
+// ResetNamedLevel removes the override of name.
+func ResetNamedLevel(name string) {
+	levels.update(func() { delete(levels.overrides, name) })
+}
+
+// SetNamedLevels replaces all the overrides.
+func SetNamedLevels(overrides map[string]slog.Level) {
+	levels.update(func() {
+		levels.overrides = make(map[string]slog.Level, len(overrides))
+		for name, level := range overrides {
+			levels.overrides[name] = level
+		}
+	})
+}
+
+// NamedLevels returns the overrides by name.
+func NamedLevels() map[string]slog.Level {
+	levels.lock.Lock()
+	defer levels.lock.Unlock()
+	overrides := make(map[string]slog.Level, len(levels.overrides))
+	for name, level := range levels.overrides {
+		overrides[name] = level
+	}
+	return overrides
+}
+
+// Named returns a logger named name writing to the outputs of the package
+// level functions.
+func Named(name string) *StdLog {
+	return stdLog().direct().Named(name)
+}
+
+// leveledHandler gates a handler with a level that can change at runtime.
+type leveledHandler struct {
+	slog.Handler
+	level slog.Leveler
+}
+
+func (h *leveledHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *leveledHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &leveledHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *leveledHandler) WithGroup(name string) slog.Handler {
+	return &leveledHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
@@ -0,0 +1,92 @@
+package logs
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+var errEmptyLevel = errors.New("logs: a level or a name to reset is required")
+
+// levelsState is the body of the responses of LevelHandler.
+type levelsState struct {
+	Level  slog.Level            `json:"level"`
+	Levels map[string]slog.Level `json:"levels"`
+}
+
+// levelChange is the body of the requests of LevelHandler.
+type levelChange struct {
+	// Name is the named logger changed, the root level when empty.
+	Name string `json:"name"`
+	// Level is the new level, an empty level resets the level of Name.
+	Level string `json:"level"`
+}
+
+// LevelHandler returns a handler reading and changing the levels at runtime.
+//
+//	GET               {"level":"INFO","levels":{"config":"DEBUG"}}
+//	PUT {"level":"debug"}                  sets the root level
+//	PUT {"name":"config","level":"debug"}  overrides the level of config
+//	PUT {"name":"config"}                  resets the level of config
+//
+// PUT and POST also accept the name and level query parameters, and
+// respond with the levels after the change. A hertz route serves it
+// through the request and response adaptors of hertz:
+//
+//	handler := logs.LevelHandler()
+//	h.Any("/debug/log/level", func(ctx context.Context, c *app.RequestContext) {
+//		req, err := adaptor.GetCompatRequest(&c.Request)
+//		if err != nil {
+//			c.String(http.StatusBadRequest, err.Error())
+//			return
+//		}
+//		handler.ServeHTTP(adaptor.GetCompatResponseWriter(&c.Response), req.WithContext(ctx))
+//	})
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(serveLevels)
+}
+
+func serveLevels(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		change := levelChange{Name: r.URL.Query().Get("name"), Level: r.URL.Query().Get("level")}
+		if r.ContentLength != 0 && r.Body != nil {
+			if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+				http.Error(w, "logs: decode body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if err := change.apply(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(levelsState{Level: GetLevel(), Levels: NamedLevels()})
+}
+
+func (c levelChange) apply() error {
+	if c.Level == "" {
+		if c.Name == "" {
+			return errEmptyLevel
+		}
+		ResetNamedLevel(c.Name)
+		return nil
+	}
+	level, err := parseLevel(c.Level)
+	if err != nil {
+		return err
+	}
+	if c.Name == "" {
+		SetLevel(level)
+	} else {
+		SetNamedLevel(c.Name, level)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// resetLevels restores the default levels when the test ends.
+func resetLevels(t *testing.T) {
+	t.Cleanup(func() {
+		SetLevel(slog.LevelInfo)
+		SetNamedLevels(nil)
+	})
+}
+
+func TestNamedLevels(t *testing.T) {
+	resetLevels(t)
+	var buf bytes.Buffer
+	root := NewLogger(WithOutputs(Output{Format: FormatLogfmt, Writer: &buf}))
+	cfg := root.Named("config")
+	file := cfg.Named("file")
+	fixed := NewLogger(WithLevel(slog.LevelError), WithOutputs(Output{Format: FormatLogfmt, Writer: &buf}))
+
+	logged := func(l *StdLog, msg string) bool {
+		buf.Reset()
+		l.Debug(msg)
+		return strings.Contains(buf.String(), msg)
+	}
+
+	if logged(root, "root") || logged(file, "file") {
+		t.Fatalf("debug logged at the default level: %q", buf.String())
+	}
+
+	SetNamedLevel("config", slog.LevelDebug)
+	if logged(root, "root") || !logged(cfg, "config") || !logged(file, "file") {
+		t.Errorf("config override not applied: %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "logger=config.file") {
+		t.Errorf("logger name missing: %q", buf.String())
+	}
+
+	SetNamedLevel("config.file", slog.LevelWarn)
+	if !logged(cfg, "config") || logged(file, "file") {
+		t.Errorf("config.file override not applied: %q", buf.String())
+	}
+
+	ResetNamedLevel("config.file")
+	ResetNamedLevel("config")
+	SetLevel(slog.LevelDebug)
+	if !logged(root, "root") || !logged(file, "file") || logged(fixed, "fixed") {
+		t.Errorf("root level not applied: %q", buf.String())
+	}
+	if GetLevel() != slog.LevelDebug {
+		t.Errorf("level want: DEBUG, got: %s", GetLevel())
+	}
+}
+
+func TestApplyLevels(t *testing.T) {
+	resetLevels(t)
+	c := Config{Level: "warn", Levels: map[string]string{"config": "debug"}}
+	if err := c.ApplyLevels(); err != nil {
+		t.Fatal(err)
+	}
+	if GetLevel() != slog.LevelWarn || NamedLevels()["config"] != slog.LevelDebug {
+		t.Errorf("unexpected levels %s %v", GetLevel(), NamedLevels())
+	}
+
+	bad := Config{Level: "debug", Levels: map[string]string{"config": "verbose"}}
+	if err := bad.ApplyLevels(); err == nil {
+		t.Error("expected an error for an invalid level")
+	}
+	if GetLevel() != slog.LevelWarn || len(NamedLevels()) != 1 {
+		t.Errorf("levels changed by an invalid config: %s %v", GetLevel(), NamedLevels())
+	}
+
+	SetNamedLevel("http", slog.LevelError)
+	if err := (&Config{Level: "info"}).ApplyLevels(); err != nil {
+		t.Fatal(err)
+	}
+	if GetLevel() != slog.LevelInfo || len(NamedLevels()) != 2 {
+		t.Errorf("named levels changed by a config without levels: %v", NamedLevels())
+	}
+	if err := (&Config{Levels: map[string]string{}}).ApplyLevels(); err != nil {
+		t.Fatal(err)
+	}
+	if len(NamedLevels()) != 0 {
+		t.Errorf("named levels not replaced by empty levels: %v", NamedLevels())
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	resetLevels(t)
+	srv := httptest.NewServer(LevelHandler())
+	defer srv.Close()
+
+	do := func(method, query, body string) (int, levelsState) {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+query, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var state levelsState
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return resp.StatusCode, state
+	}
+
+	if code, state := do(http.MethodGet, "", ""); code != http.StatusOK || state.Level != slog.LevelInfo {
+		t.Errorf("GET: %d %+v", code, state)
+	}
+	if code, state := do(http.MethodPut, "", `{"level":"debug"}`); code != http.StatusOK || state.Level != slog.LevelDebug {
+		t.Errorf("PUT root: %d %+v", code, state)
+	}
+	if code, state := do(http.MethodPost, "?name=config&level=warn", ""); code != http.StatusOK || state.Levels["config"] != slog.LevelWarn {
+		t.Errorf("POST query: %d %+v", code, state)
+	}
+	if code, state := do(http.MethodPut, "", `{"name":"config"}`); code != http.StatusOK || len(state.Levels) != 0 {
+		t.Errorf("PUT reset: %d %+v", code, state)
+	}
+	for _, bad := range []string{`{"level":"verbose"}`, `{}`, `{`} {
+		if code, _ := do(http.MethodPut, "", bad); code != http.StatusBadRequest {
+			t.Errorf("PUT %s: want 400, got %d", bad, code)
+		}
+	}
+	if code, _ := do(http.MethodDelete, "", ""); code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE: want 405, got %d", code)
+	}
+}
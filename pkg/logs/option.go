@@ -1,14 +1,27 @@
 package logs
 
+import (
+	"log/slog"
+
+	"github.com/banbridge/common/pkg/logs/handler"
+)
+
 type LoggerOption func(l *SlogOption)
 
 type SlogOption struct {
 	CallDepth int
+	// Level is the minimum level logged, the level set by SetLevel when nil.
+	Level slog.Leveler
+	// Outputs receive the records, colored text on stdout by default.
+	Outputs []Output
+	// Async writes the records from a goroutine when it is not nil.
+	Async *handler.AsyncOptions
 }
 
 func getDefaultOpt() *SlogOption {
 	return &SlogOption{
 		CallDepth: 1,
+		Outputs:   []Output{{Target: TargetStdout, Format: FormatText}},
 	}
 }
 
@@ -17,3 +30,26 @@ func WithCallDepth(callDepth int) LoggerOption {
 		l.CallDepth = callDepth
 	}
 }
+
+// WithLevel logs the records of level and above, the logger no longer
+// follows SetLevel.
+func WithLevel(level slog.Leveler) LoggerOption {
+	return func(l *SlogOption) {
+		l.Level = level
+	}
+}
+
+// WithOutputs replaces the outputs of the logger, no output discards the records.
+func WithOutputs(outputs ...Output) LoggerOption {
+	return func(l *SlogOption) {
+		l.Outputs = outputs
+	}
+}
+
+// WithAsync buffers the records and writes them to the outputs from a
+// goroutine, see handler.AsyncHandler. Sync and Close flush the buffer.
+func WithAsync(opts handler.AsyncOptions) LoggerOption {
+	return func(l *SlogOption) {
+		l.Async = &opts
+	}
+}
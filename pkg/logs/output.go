@@ -0,0 +1,151 @@
+package logs
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/natefinch/lumberjack"
+
+	"github.com/banbridge/common/pkg/logs/handler"
+)
+
+// Target is where an output writes.
+type Target string
+
+const (
+	TargetStdout Target = "stdout"
+	TargetStderr Target = "stderr"
+	TargetFile   Target = "file"
+	// TargetNone discards the records.
+	TargetNone Target = "none"
+)
+
+// Format is how an output encodes the records.
+type Format string
+
+const (
+	// FormatText is the console format, colored when written to a terminal.
+	FormatText Format = "text"
+	// FormatJSON is one json object per record.
+	FormatJSON Format = "json"
+	// FormatLogfmt is key=value pairs, one line per record.
+	FormatLogfmt Format = "logfmt"
+)
+
+// Output is a destination of the records.
+type Output struct {
+	Target Target `json:"target"`
+	Format Format `json:"format"`
+	// File is the file and its rotation when Target is file.
+	File FileOptions `json:"file"`
+	// Writer receives the records instead of Target when it is set.
+	Writer io.Writer `json:"-"`
+}
+
+// FileOptions is the rotation policy of a file output, see lumberjack.Logger.
+type FileOptions struct {
+	// Path is the file name, logs/<date>_<hour>.log of the process start when empty.
+	Path string `json:"path"`
+	// MaxSize is the size in megabytes a file is rotated at, 100 when 0.
+	MaxSize int `json:"max_size"`
+	// MaxBackups is the number of rotated files kept, 0 keeps all of them.
+	MaxBackups int `json:"max_backups"`
+	// MaxAge is the number of days rotated files are kept, 0 keeps them forever.
+	MaxAge    int  `json:"max_age"`
+	Compress  bool `json:"compress"`
+	LocalTime bool `json:"local_time"`
+}
+
+// Stdout returns an output writing format to stdout.
+func Stdout(format Format) Output {
+	return Output{Target: TargetStdout, Format: format}
+}
+
+// Stderr returns an output writing format to stderr.
+func Stderr(format Format) Output {
+	return Output{Target: TargetStderr, Format: format}
+}
+
+// File returns an output writing format to a rotated file.
+func File(format Format, opts FileOptions) Output {
+	return Output{Target: TargetFile, Format: format, File: opts}
+}
+
+// validate reports the targets and formats that are not supported.
+func (o Output) validate() error {
+	switch o.Target {
+	case TargetStdout, TargetStderr, TargetFile, TargetNone, "":
+	default:
+		return fmt.Errorf("logs: unknown output target %q", o.Target)
+	}
+	switch o.Format {
+	case FormatText, FormatJSON, FormatLogfmt, "":
+	default:
+		return fmt.Errorf("logs: unknown output format %q", o.Format)
+	}
+	return nil
+}
+
+// writer returns the writer of the output, and the closer of the writers
+// opened for it. A nil writer discards the records.
+func (o Output) writer() (io.Writer, io.Closer) {
+	if o.Writer != nil {
+		return o.Writer, nil
+	}
+	switch o.Target {
+	case TargetStdout, "":
+		return os.Stdout, nil
+	case TargetStderr:
+		return os.Stderr, nil
+	case TargetFile:
+		file := newLogFile(o.File)
+		return file, file
+	}
+	return nil, nil
+}
+
+// handler returns the handler of the output, nil if it discards the records.
+func (o Output) handler(level slog.Leveler) (slog.Handler, io.Closer) {
+	w, closer := o.writer()
+	if w == nil {
+		return nil, nil
+	}
+	switch o.Format {
+	case FormatJSON:
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: level}), closer
+	case FormatLogfmt:
+		return slog.NewTextHandler(w, &slog.HandlerOptions{AddSource: true, Level: level}), closer
+	default:
+		return handler.NewConsoleHandler(w, &handler.ConsoleOptions{AddSource: true, Level: level, NoColor: !isTerminal(w)}), closer
+	}
+}
+
+// isTerminal reports whether w is a terminal, the text written to files,
+// pipes and other writers has no color codes.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
+
+// newLogFile opens a rotated file, the directory is created by the first write.
+func newLogFile(opts FileOptions) *lumberjack.Logger {
+	name := opts.Path
+	if name == "" {
+		// 将文件名设置为日期
+		name = path.Join("logs", time.Now().Format("2006-01-02_15")+".log")
+	}
+	// 提供压缩和删除
+	return &lumberjack.Logger{
+		Filename:   name,
+		MaxSize:    opts.MaxSize,
+		MaxBackups: opts.MaxBackups,
+		MaxAge:     opts.MaxAge,
+		Compress:   opts.Compress,
+		LocalTime:  opts.LocalTime,
+	}
+}
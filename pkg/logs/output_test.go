@@ -0,0 +1,122 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestOutputs(t *testing.T) {
+	var text, js, logfmt bytes.Buffer
+	l := NewLogger(
+		WithLevel(slog.LevelWarn),
+		WithOutputs(
+			Output{Format: FormatText, Writer: &text},
+			Output{Format: FormatJSON, Writer: &js},
+			Output{Format: FormatLogfmt, Writer: &logfmt},
+			Output{Target: TargetNone},
+		),
+	)
+	l.Info("dropped")
+	l.Warn("disk %d%% full", 90)
+
+	if strings.Contains(text.String(), "dropped") || !strings.Contains(text.String(), "disk 90% full") {
+		t.Errorf("unexpected text output %q", text.String())
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(js.Bytes(), &record); err != nil {
+		t.Fatalf("invalid json output %q: %v", js.String(), err)
+	}
+	if record["msg"] != "disk 90% full" || record["level"] != "WARN" {
+		t.Errorf("unexpected json record %v", record)
+	}
+	if !strings.Contains(logfmt.String(), `level=WARN`) || !strings.Contains(logfmt.String(), `msg="disk 90% full"`) {
+		t.Errorf("unexpected logfmt output %q", logfmt.String())
+	}
+}
+
+func TestFileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := NewLogger(WithOutputs(File(FormatJSON, FileOptions{Path: path, MaxSize: 1})))
+	l.Error("written")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"msg":"written"`) {
+		t.Errorf("unexpected file content %q", data)
+	}
+}
+
+func TestTextFileOutputHasNoColor(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = false
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := NewLogger(WithOutputs(File(FormatText, FileOptions{Path: path})))
+	l.Error("written")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "[ERROR]") || strings.Contains(string(data), "\x1b[") {
+		t.Errorf("unexpected file content %q", data)
+	}
+}
+
+// jsonScanner scans a json document like a config.Value does.
+type jsonScanner string
+
+func (s jsonScanner) Scan(v interface{}) error {
+	return json.Unmarshal([]byte(s), v)
+}
+
+func TestNewLoggerFromConfig(t *testing.T) {
+	defer SetLevel(GetLevel())
+	defer SetNamedLevels(NamedLevels())
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := NewLoggerFromConfig(jsonScanner(`{
+		"level": "debug",
+		"levels": {"db": "warn"},
+		"outputs": [{"target": "file", "format": "logfmt", "file": {"path": "` + filepath.ToSlash(path) + `"}}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if GetLevel() != slog.LevelDebug || NamedLevels()["db"] != slog.LevelWarn {
+		t.Errorf("levels not applied: %v %v", GetLevel(), NamedLevels())
+	}
+	l.Debug("configured")
+	// the logger follows the runtime level
+	SetLevel(slog.LevelInfo)
+	l.Debug("dropped")
+	_ = l.Close()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "level=DEBUG") || strings.Contains(string(data), "dropped") {
+		t.Errorf("unexpected file content %q", data)
+	}
+
+	for _, bad := range []string{
+		`{"level": "verbose"}`,
+		`{"outputs": [{"target": "syslog"}]}`,
+		`{"outputs": [{"format": "xml"}]}`,
+	} {
+		if _, err := NewLoggerFromConfig(jsonScanner(bad)); err == nil {
+			t.Errorf("expected an error for %s", bad)
+		}
+	}
+}
@@ -4,14 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"os"
-	"path"
+	"log/slog"
 	"runtime"
 	"time"
 
-	"github.com/natefinch/lumberjack"
-	"log/slog"
-
 	"github.com/banbridge/common/pkg/ctx_values"
 	"github.com/banbridge/common/pkg/logs/handler"
 )
@@ -19,11 +15,35 @@ import (
 // StdLog 自定义日志记录器
 type StdLog struct {
 	inner *slog.Logger
-	op    *SlogOption
+	// handler is the handler of the outputs with the attrs and groups of
+	// With and WithGroup, it does not filter the records.
+	handler slog.Handler
+	// root is handler before the first group, the attrs of the context
+	// are added to it so they are not nested in the groups.
+	root    slog.Handler
+	scopes  []scope
+	level   slog.Leveler
+	name    string
+	op      *SlogOption
+	async   *handler.AsyncHandler
+	closers []io.Closer
+	// shared is set for the loggers derived from another one, they do
+	// not close its files and buffer.
+	shared bool
+}
+
+// scope is a group opened by WithGroup and the attrs added in it.
+type scope struct {
+	group string
+	attrs []slog.Attr
 }
 
-var _ Logger = &StdLog{}
+var _ StructuredLogger = &StdLog{}
 
+// NewLogger new a logger, it logs colored text to stdout unless
+// WithOutputs is given. It no longer writes json to logs/<date>_<hour>.log
+// by default, add File(FormatJSON, FileOptions{}) to the outputs for that,
+// and see SetDefault to configure the package level functions.
 func NewLogger(opts ...LoggerOption) *StdLog {
 
 	logOpt := getDefaultOpt()
@@ -32,82 +52,263 @@ func NewLogger(opts ...LoggerOption) *StdLog {
 		opt(logOpt)
 	}
 
-	// 控制台输出（文本格式）
-	consoleHandler := handler.NewConsoleHandler(os.Stdout, nil)
+	var (
+		handlers handler.MultiHandler
+		closers  []io.Closer
+	)
+	for _, output := range logOpt.Outputs {
+		h, closer := output.handler(levelAll)
+		if h != nil {
+			handlers = append(handlers, h)
+		}
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+	}
+
+	level := logOpt.Level
+	if level == nil {
+		level = levels.leveler("")
+	}
+	l := &StdLog{
+		handler: handlers,
+		root:    handlers,
+		level:   level,
+		op:      logOpt,
+		closers: closers,
+	}
+	if logOpt.Async != nil {
+		l.async = handler.NewAsyncHandler(handlers, logOpt.Async)
+		l.handler, l.root = l.async, l.async
+		asyncs.add(l.async)
+	}
+	l.inner = slog.New(&leveledHandler{Handler: l.handler, level: l.level})
+	return l
+}
+
+// Slog returns a slog.Logger writing to the outputs of l with its level,
+// attrs and groups, for the libraries taking a *slog.Logger. It does not
+// add the log_id and the attrs of the context.
+func (l *StdLog) Slog() *slog.Logger {
+	return l.inner
+}
+
+// child returns a copy of l sharing its files, only l closes them.
+func (l *StdLog) child() *StdLog {
+	c := *l
+	c.closers = nil
+	c.shared = true
+	c.inner = nil
+	return &c
+}
+
+// build sets the slog.Logger of a logger returned by child.
+func (l *StdLog) build() *StdLog {
+	l.inner = slog.New(&leveledHandler{Handler: l.handler, level: l.level})
+	return l
+}
+
+// direct returns a copy of the package level logger for the callers that
+// log with it directly, without the frame of the package level functions.
+func (l *StdLog) direct() *StdLog {
+	return l.withCallDepth(0)
+}
+
+// withCallDepth returns a copy of l skipping depth frames more than its
+// log methods, it shares the files of l.
+func (l *StdLog) withCallDepth(depth int) *StdLog {
+	c := l.child()
+	op := *l.op
+	op.CallDepth = depth
+	c.op = &op
+	return c.build()
+}
+
+// Named returns a logger writing to the outputs of l whose level is the
+// one of name in the registry, see SetNamedLevel. The name of a named
+// logger is joined to its parent's with a dot and logged as "logger".
+// The returned logger shares the files of l, only l closes them.
+func (l *StdLog) Named(name string) *StdLog {
+	c := l.child()
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	c.name = name
+	c.level = levels.leveler(name)
+	return c.build()
+}
+
+// With returns a logger adding args to every record, args are key-value
+// pairs or slog.Attr like the args of slog.Logger.Info. The returned
+// logger shares the files of l, only l closes them.
+func (l *StdLog) With(args ...any) *StdLog {
+	attrs := argsToAttrs(args)
+	if len(attrs) == 0 {
+		return l
+	}
+	c := l.child()
+	c.handler = l.handler.WithAttrs(attrs)
+	if len(l.scopes) == 0 {
+		c.root = l.root.WithAttrs(attrs)
+	} else {
+		c.scopes = append([]scope(nil), l.scopes...)
+		last := &c.scopes[len(c.scopes)-1]
+		last.attrs = append(append([]slog.Attr(nil), last.attrs...), attrs...)
+	}
+	return c.build()
+}
 
-	// 文件输出（JSON格式）
-	file := mustOpenLogFile("logs")
-	fileHandler := slog.NewJSONHandler(file, &slog.HandlerOptions{
-		AddSource: true,
+// WithGroup returns a logger nesting the attrs of With and of the records
+// in the group name, the log_id, logger and context attrs stay at the top
+// level. The returned logger shares the files of l, only l closes them.
+func (l *StdLog) WithGroup(name string) *StdLog {
+	if name == "" {
+		return l
+	}
+	c := l.child()
+	c.handler = l.handler.WithGroup(name)
+	c.scopes = append(append([]scope(nil), l.scopes...), scope{group: name})
+	return c.build()
+}
+
+// argsToAttrs converts key-value pairs and slog.Attr to attrs the way
+// slog.Record.Add does.
+func argsToAttrs(args []any) []slog.Attr {
+	if len(args) == 0 {
+		return nil
+	}
+	var r slog.Record
+	r.Add(args...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
 	})
+	return attrs
+}
 
-	return &StdLog{
-		inner: slog.New(handler.MultiHandler{consoleHandler, fileHandler}),
-		op:    logOpt,
+// Sync waits for the buffered records to be written, it returns at once
+// if the logger is not async.
+func (l *StdLog) Sync(ctx context.Context) error {
+	if l.async == nil {
+		return nil
 	}
+	return l.async.Sync(ctx)
 }
 
-func mustOpenLogFile(dir string) io.Writer {
-	// ======================输出文件============================
-	// 将文件名设置为日期
-	logFileName := time.Now().Format("2006-01-02_15") + ".log"
-	fileName := path.Join(dir, logFileName)
-	// 提供压缩和删除
-	lumberjackLogger := &lumberjack.Logger{
-		Filename:   fileName,
-		MaxSize:    200,  // 一个文件最大可达 20M。
-		MaxBackups: 50,   // 最多同时保存 5 个文件。
-		MaxAge:     10,   // 一个文件最多可以保存 10 天。
-		Compress:   true, // 用 gzip 压缩。
+// Stats returns the counters of the buffered records, zero if the logger
+// is not async.
+func (l *StdLog) Stats() handler.AsyncStats {
+	if l.async == nil {
+		return handler.AsyncStats{}
 	}
-	defer lumberjackLogger.Close()
+	return l.async.Stats()
+}
 
-	return lumberjackLogger
+// Close writes the buffered records and closes the files of the outputs,
+// the records logged afterwards are written synchronously.
+func (l *StdLog) Close() error {
+	if l.shared {
+		return nil
+	}
+	var err error
+	if l.async != nil {
+		err = l.async.Close(context.Background())
+		asyncs.remove(l.async)
+	}
+	for _, c := range l.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
 }
 
 func (l *StdLog) Info(msg string, args ...any) {
-	l.log(context.Background(), slog.LevelInfo, msg, args...)
+	l.log(context.Background(), slog.LevelInfo, msg, true, args)
 }
 
 func (l *StdLog) Warn(msg string, args ...any) {
-	l.log(context.Background(), slog.LevelWarn, msg, args...)
+	l.log(context.Background(), slog.LevelWarn, msg, true, args)
 }
 
 func (l *StdLog) Error(msg string, args ...any) {
-	l.log(context.Background(), slog.LevelError, msg, args...)
+	l.log(context.Background(), slog.LevelError, msg, true, args)
 }
 
 func (l *StdLog) Debug(msg string, args ...any) {
-	l.log(context.Background(), slog.LevelDebug, msg, args...)
+	l.log(context.Background(), slog.LevelDebug, msg, true, args)
 }
 
 func (l *StdLog) CtxDebug(ctx context.Context, msg string, args ...any) {
-	l.log(ctx, slog.LevelDebug, msg, args...)
+	l.log(ctx, slog.LevelDebug, msg, true, args)
 }
 
 func (l *StdLog) CtxWarn(ctx context.Context, msg string, args ...any) {
-	l.log(ctx, slog.LevelWarn, msg, args...)
+	l.log(ctx, slog.LevelWarn, msg, true, args)
 }
 
 func (l *StdLog) CtxInfo(ctx context.Context, msg string, args ...any) {
-	l.log(ctx, slog.LevelInfo, msg, args...)
+	l.log(ctx, slog.LevelInfo, msg, true, args)
 }
 
 func (l *StdLog) CtxError(ctx context.Context, msg string, args ...any) {
-	l.log(ctx, slog.LevelError, msg, args...)
+	l.log(ctx, slog.LevelError, msg, true, args)
+}
+
+// Infow logs msg with the attrs args, key-value pairs or slog.Attr like
+// the args of slog.Logger.Info, msg is not formatted.
+func (l *StdLog) Infow(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelInfo, msg, false, args)
+}
+
+func (l *StdLog) Warnw(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelWarn, msg, false, args)
+}
+
+func (l *StdLog) Errorw(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelError, msg, false, args)
+}
+
+func (l *StdLog) Debugw(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelDebug, msg, false, args)
 }
 
-func (l *StdLog) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+func (l *StdLog) CtxDebugw(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelDebug, msg, false, args)
+}
+
+func (l *StdLog) CtxWarnw(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelWarn, msg, false, args)
+}
+
+func (l *StdLog) CtxInfow(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelInfo, msg, false, args)
+}
+
+func (l *StdLog) CtxErrorw(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelError, msg, false, args)
+}
+
+// log formats msg with args when printf is set, otherwise args are the
+// attrs of the record.
+func (l *StdLog) log(ctx context.Context, level slog.Level, msg string, printf bool, args []any) {
+	if !l.inner.Enabled(ctx, level) {
+		return
+	}
 	var pcs [1]uintptr
 	runtime.Callers(3+l.op.CallDepth, pcs[:]) // 3 = log + Info/Warn/Error + runtime.Callers
 
-	if len(args) > 0 {
+	if printf && len(args) > 0 {
 		msg = fmt.Sprintf(msg, args...)
 	}
 
 	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
 
 	var attrs []slog.Attr
+	if l.name != "" {
+		attrs = append(attrs, slog.String("logger", l.name))
+	}
 
 	logID := LogIDDefault(ctx)
 	if logID != "-" {
@@ -121,7 +322,33 @@ func (l *StdLog) log(ctx context.Context, level slog.Level, msg string, args ...
 		attrs = append(attrs, slog.Any(str, kvs[i+1]))
 	}
 
-	r.AddAttrs(attrs...)
-	//r.Add(args...)
-	_ = l.inner.Handler().Handle(context.Background(), r)
+	h := l.handler
+	if len(l.scopes) > 0 && len(attrs) > 0 {
+		// keep the attrs of the context out of the groups, the groups are
+		// added to the record instead of the handler
+		h = l.root
+		r.AddAttrs(attrs...)
+		var recordAttrs []slog.Attr
+		if !printf {
+			recordAttrs = argsToAttrs(args)
+		}
+		r.AddAttrs(l.nest(recordAttrs))
+	} else {
+		r.AddAttrs(attrs...)
+		if !printf {
+			r.Add(args...)
+		}
+	}
+	_ = h.Handle(context.Background(), r)
+}
+
+// nest returns attrs in the groups of the scopes of l, with the attrs
+// added in each group.
+func (l *StdLog) nest(attrs []slog.Attr) slog.Attr {
+	for i := len(l.scopes) - 1; i >= 0; i-- {
+		s := l.scopes[i]
+		group := append(append([]slog.Attr(nil), s.attrs...), attrs...)
+		attrs = []slog.Attr{{Key: s.group, Value: slog.GroupValue(group...)}}
+	}
+	return attrs[0]
 }
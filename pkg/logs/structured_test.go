@@ -0,0 +1,71 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStructured(t *testing.T) {
+	var js, text bytes.Buffer
+	l := NewLogger(WithOutputs(
+		Output{Format: FormatJSON, Writer: &js},
+		Output{Format: FormatText, Writer: &text},
+	))
+	ctx := SetLogID(context.Background(), "abc")
+
+	l.With("service", "api").WithGroup("req").With("method", "GET").
+		CtxInfow(ctx, "100% done", "latency", time.Second, slog.Int("status", 200))
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(js.Bytes(), &record); err != nil {
+		t.Fatalf("invalid json output %q: %v", js.String(), err)
+	}
+	if record["msg"] != "100% done" || record["service"] != "api" || record["log_id"] != "abc" {
+		t.Errorf("unexpected json record %v", record)
+	}
+	req, _ := record["req"].(map[string]interface{})
+	if req["method"] != "GET" || req["latency"] != float64(time.Second) || req["status"] != float64(200) {
+		t.Errorf("unexpected req group %v", record["req"])
+	}
+	for _, want := range []string{"service=api", "req.method=GET", "req.latency=1s", "req.status=200", "log_id=abc"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text output %q misses %s", text.String(), want)
+		}
+	}
+
+	js.Reset()
+	l.Infow("odd", "key")
+	if !strings.Contains(js.String(), `"!BADKEY":"key"`) {
+		t.Errorf("unexpected json output %q", js.String())
+	}
+	js.Reset()
+	l.Info("printf %d", 1)
+	if !strings.Contains(js.String(), `"msg":"printf 1"`) {
+		t.Errorf("unexpected json output %q", js.String())
+	}
+}
+
+func TestSlog(t *testing.T) {
+	var text bytes.Buffer
+	l := NewLogger(WithLevel(slog.LevelWarn), WithOutputs(Output{Format: FormatText, Writer: &text}))
+	sl := l.Slog().With("lib", "x").WithGroup("g")
+	sl.Info("dropped")
+	sl.Warn("kept", "k", "v")
+	if strings.Contains(text.String(), "dropped") || !strings.Contains(text.String(), "lib=x g.k=v") {
+		t.Errorf("unexpected text output %q", text.String())
+	}
+}
+
+func TestWithCaller(t *testing.T) {
+	var js bytes.Buffer
+	l := NewLogger(WithCallDepth(0), WithOutputs(Output{Format: FormatJSON, Writer: &js}))
+	l.With("a", 1).Infow("caller")
+	if !strings.Contains(js.String(), "structured_test.go") {
+		t.Errorf("unexpected source %q", js.String())
+	}
+}